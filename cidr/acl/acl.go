@@ -0,0 +1,155 @@
+// Package acl renders lists of prefixes as firewall rules.
+package acl
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Action is what a rule does with matching packets.
+type Action string
+
+const (
+	Allow Action = "allow"
+	Deny  Action = "deny"
+)
+
+// Direction is the direction of the traffic a rule applies to.
+type Direction string
+
+const (
+	In  Direction = "in"
+	Out Direction = "out"
+)
+
+// Field is the packet address that is matched against the prefixes.
+type Field string
+
+const (
+	Source      Field = "source"
+	Destination Field = "destination"
+)
+
+// Policy describes the rules to render.
+type Policy struct {
+	// Name names the set, table, ACL or filter. It must not contain whitespace.
+	Name      string
+	Action    Action
+	Direction Direction
+	// Match is the address that is matched. If empty, inbound rules match
+	// the source and outbound rules match the destination.
+	Match Field
+	// Interface optionally restricts the rules to an interface.
+	Interface string
+	Prefixes  []netip.Prefix
+}
+
+// Renderer writes a policy in the syntax of one firewall.
+type Renderer interface {
+	Render(w io.Writer, p Policy) error
+}
+
+var availableSyntaxes = map[string]Renderer{
+	"iptables": iptables{},
+	"nftables": nftables{},
+	"pf":       pf{},
+	"cisco":    cisco{},
+	"juniper":  juniper{},
+}
+
+// Syntaxes returns the names of the supported syntaxes.
+func Syntaxes() []string {
+	keys := make([]string, 0, len(availableSyntaxes))
+	for k := range availableSyntaxes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetRenderer returns the renderer for the named syntax.
+func GetRenderer(name string) (Renderer, error) {
+	if r, ok := availableSyntaxes[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return r, nil
+	}
+	return nil, fmt.Errorf("unsupported syntax %q (choose one of: %s)",
+		name, strings.Join(Syntaxes(), ", "))
+}
+
+// Render validates p and writes it with r.
+func Render(w io.Writer, r Renderer, p Policy) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	return r.Render(w, p)
+}
+
+func (p Policy) validate() error {
+	if p.Name == "" || strings.IndexFunc(p.Name, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid name %q: must be non-empty and contain no whitespace", p.Name)
+	}
+	if strings.IndexFunc(p.Interface, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid interface %q: must not contain whitespace", p.Interface)
+	}
+	switch p.Action {
+	case Allow, Deny:
+	default:
+		return fmt.Errorf("invalid action %q (choose one of: allow, deny)", p.Action)
+	}
+	switch p.Direction {
+	case In, Out:
+	default:
+		return fmt.Errorf("invalid direction %q (choose one of: in, out)", p.Direction)
+	}
+	switch p.Match {
+	case "", Source, Destination:
+	default:
+		return fmt.Errorf("invalid match %q (choose one of: source, destination)", p.Match)
+	}
+	if len(p.Prefixes) == 0 {
+		return fmt.Errorf("no prefixes given")
+	}
+	return nil
+}
+
+// field returns the matched address, defaulting from the direction.
+func (p Policy) field() Field {
+	if p.Match != "" {
+		return p.Match
+	}
+	if p.Direction == Out {
+		return Destination
+	}
+	return Source
+}
+
+// families splits the prefixes by family, keeping their order. Host bits are cleared,
+// since most firewalls reject or silently mask them.
+func (p Policy) families() (v4s, v6s []netip.Prefix) {
+	for _, pfx := range p.Prefixes {
+		pfx = pfx.Masked()
+		if pfx.Addr().Is4() {
+			v4s = append(v4s, pfx)
+		} else {
+			v6s = append(v6s, pfx)
+		}
+	}
+	return v4s, v6s
+}
+
+// errWriter remembers the first write error so renderers can write line by line.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
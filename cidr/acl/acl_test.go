@@ -0,0 +1,67 @@
+package acl
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+// Wildcard masks for lengths off an octet boundary, and none for v6.
+func TestWildcard(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.0/8":      "0.255.255.255",
+		"172.16.0.0/12":   "0.15.255.255",
+		"192.0.2.64/26":   "0.0.0.63",
+		"192.0.2.1/32":    "0.0.0.0",
+		"0.0.0.0/0":       "255.255.255.255",
+		"10.1.2.128/25":   "0.0.0.127",
+		"100.64.0.0/10":   "0.63.255.255",
+		"198.51.100.0/23": "0.0.1.255",
+	}
+	for in, want := range tests {
+		got, err := Wildcard(netip.MustParsePrefix(in))
+		if err != nil {
+			t.Fatalf(`Wildcard(%q) returned error: %s`, in, err)
+		}
+		if got.String() != want {
+			t.Errorf(`Wildcard(%q) = %s, want %s`, in, got, want)
+		}
+	}
+	if _, err := Wildcard(netip.MustParsePrefix("2001:db8::/32")); err == nil {
+		t.Errorf(`Wildcard("2001:db8::/32") returned no error for a v6 prefix`)
+	}
+}
+
+// A mixed policy must never put a v6 network into a v4 rule or list, in any syntax.
+func TestRenderFamilies(t *testing.T) {
+	p := Policy{
+		Name:      "edge",
+		Action:    Deny,
+		Direction: In,
+		Prefixes:  []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24"), netip.MustParsePrefix("2001:db8::/32")},
+	}
+	tests := map[string][]string{
+		"iptables": {"iptables -A INPUT -s 192.0.2.0/24 -j DROP", "ip6tables -A INPUT -s 2001:db8::/32 -j DROP"},
+		"nftables": {"type ipv4_addr", "elements = { 192.0.2.0/24 }", "elements = { 2001:db8::/32 }", "ip saddr @edge_v4 drop", "ip6 saddr @edge_v6 drop"},
+		"pf":       {"block in quick inet from <edge> to any", "block in quick inet6 from <edge> to any"},
+		"cisco":    {"ip access-list extended edge\n deny ip 192.0.2.0 0.0.0.255 any\n", "ipv6 access-list edge\n deny ipv6 2001:db8::/32 any\n"},
+		"juniper":  {"family inet filter edge term edge from source-address 192.0.2.0/24", "family inet6 filter edge term edge from source-address 2001:db8::/32"},
+	}
+	for syntax, wants := range tests {
+		t.Run(syntax, func(t *testing.T) {
+			r, err := GetRenderer(syntax)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := Render(&b, r, p); err != nil {
+				t.Fatalf(`Render returned error: %s`, err)
+			}
+			for _, want := range wants {
+				if !strings.Contains(b.String(), want) {
+					t.Errorf("output does not contain %q:\n%s", want, b.String())
+				}
+			}
+		})
+	}
+}
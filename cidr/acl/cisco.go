@@ -0,0 +1,99 @@
+package acl
+
+import (
+	"fmt"
+	"io"
+	"net/netip"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+)
+
+// cisco renders an extended IPv4 access list using wildcard masks and an IPv6 access list,
+// since IOS keeps the two families in separate lists.
+type cisco struct{}
+
+func (cisco) Render(w io.Writer, p Policy) error {
+	action := "permit"
+	if p.Action == Deny {
+		action = "deny"
+	}
+	v4s, v6s := p.families()
+
+	ew := &errWriter{w: w}
+	if len(v4s) > 0 {
+		ew.printf("ip access-list extended %s\n", p.Name)
+		for _, pfx := range v4s {
+			addr, err := wildcardAddress(pfx)
+			if err != nil {
+				return err
+			}
+			ew.printf(" %s ip %s\n", action, p.endpoints(addr))
+		}
+		ew.printf("!\n")
+	}
+	if len(v6s) > 0 {
+		ew.printf("ipv6 access-list %s\n", p.Name)
+		for _, pfx := range v6s {
+			addr := pfx.String()
+			switch {
+			case pfx.Bits() == 0:
+				addr = "any"
+			case pfx.IsSingleIP():
+				addr = "host " + pfx.Addr().String()
+			}
+			ew.printf(" %s ipv6 %s\n", action, p.endpoints(addr))
+		}
+		ew.printf("!\n")
+	}
+	if p.Interface != "" {
+		ew.printf("interface %s\n", p.Interface)
+		if len(v4s) > 0 {
+			ew.printf(" ip access-group %s %s\n", p.Name, p.Direction)
+		}
+		if len(v6s) > 0 {
+			ew.printf(" ipv6 traffic-filter %s %s\n", p.Name, p.Direction)
+		}
+		ew.printf("!\n")
+	}
+	return ew.err
+}
+
+// endpoints orders the matched address and "any" as source and destination.
+func (p Policy) endpoints(addr string) string {
+	if p.field() == Destination {
+		return "any " + addr
+	}
+	return addr + " any"
+}
+
+// wildcardAddress formats a v4 prefix as "address wildcard", using the
+// "host" and "any" keywords for /32 and /0.
+func wildcardAddress(pfx netip.Prefix) (string, error) {
+	switch pfx.Bits() {
+	case 0:
+		return "any", nil
+	case 32:
+		return "host " + pfx.Addr().String(), nil
+	}
+	wc, err := Wildcard(pfx)
+	if err != nil {
+		return "", err
+	}
+	return pfx.Addr().String() + " " + wc.String(), nil
+}
+
+// Wildcard returns the inverse of the netmask of a v4 prefix, as used by Cisco ACLs.
+func Wildcard(pfx netip.Prefix) (netip.Addr, error) {
+	if !pfx.Addr().Is4() {
+		return netip.Addr{}, fmt.Errorf("wildcard masks are only defined for v4 prefixes: %s", pfx)
+	}
+	n, err := network.New(pfx.String())
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	mask := n.Netmask().As4()
+	for i := range mask {
+		mask[i] = ^mask[i]
+	}
+	return netip.AddrFrom4(mask), nil
+}
@@ -0,0 +1,40 @@
+package acl
+
+import (
+	"io"
+	"net/netip"
+)
+
+// iptables renders one command per prefix, using iptables for v4 and ip6tables for v6.
+type iptables struct{}
+
+func (iptables) Render(w io.Writer, p Policy) error {
+	chain, ifFlag := "INPUT", "-i"
+	if p.Direction == Out {
+		chain, ifFlag = "OUTPUT", "-o"
+	}
+	addrFlag := "-s"
+	if p.field() == Destination {
+		addrFlag = "-d"
+	}
+	target := "ACCEPT"
+	if p.Action == Deny {
+		target = "DROP"
+	}
+
+	ew := &errWriter{w: w}
+	v4s, v6s := p.families()
+	for _, fam := range []struct {
+		cmd      string
+		prefixes []netip.Prefix
+	}{{"iptables", v4s}, {"ip6tables", v6s}} {
+		for _, pfx := range fam.prefixes {
+			ew.printf("%s -A %s", fam.cmd, chain)
+			if p.Interface != "" {
+				ew.printf(" %s %s", ifFlag, p.Interface)
+			}
+			ew.printf(" %s %s -j %s\n", addrFlag, pfx, target)
+		}
+	}
+	return ew.err
+}
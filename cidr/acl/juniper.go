@@ -0,0 +1,50 @@
+package acl
+
+import (
+	"io"
+	"net/netip"
+	"strings"
+)
+
+// juniper renders Junos "set" commands for one firewall filter per family.
+type juniper struct{}
+
+func (juniper) Render(w io.Writer, p Policy) error {
+	then := "accept"
+	if p.Action == Deny {
+		then = "discard"
+	}
+	from := "source-address"
+	if p.field() == Destination {
+		from = "destination-address"
+	}
+	filterDir := "input"
+	if p.Direction == Out {
+		filterDir = "output"
+	}
+
+	ew := &errWriter{w: w}
+	v4s, v6s := p.families()
+	for _, fam := range []struct {
+		name     string
+		prefixes []netip.Prefix
+	}{{"inet", v4s}, {"inet6", v6s}} {
+		if len(fam.prefixes) == 0 {
+			continue
+		}
+		filter := "set firewall family " + fam.name + " filter " + p.Name + " term " + p.Name
+		for _, pfx := range fam.prefixes {
+			ew.printf("%s from %s %s\n", filter, from, pfx)
+		}
+		ew.printf("%s then %s\n", filter, then)
+		if p.Interface != "" {
+			// Interfaces are given as "name" or "name.unit"; the unit defaults to 0.
+			ifName, unit, ok := strings.Cut(p.Interface, ".")
+			if !ok {
+				unit = "0"
+			}
+			ew.printf("set interfaces %s unit %s family %s filter %s %s\n", ifName, unit, fam.name, filterDir, p.Name)
+		}
+	}
+	return ew.err
+}
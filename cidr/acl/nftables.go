@@ -0,0 +1,69 @@
+package acl
+
+import (
+	"io"
+	"net/netip"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+)
+
+// nftables renders an inet table with one named interval set per family
+// and a chain that matches both sets.
+type nftables struct{}
+
+func (nftables) Render(w io.Writer, p Policy) error {
+	hook, ifKey := "input", "iifname"
+	if p.Direction == Out {
+		hook, ifKey = "output", "oifname"
+	}
+	addrKey := "saddr"
+	if p.field() == Destination {
+		addrKey = "daddr"
+	}
+	verdict := "accept"
+	if p.Action == Deny {
+		verdict = "drop"
+	}
+
+	// Interval sets reject overlapping elements, so the prefixes are merged first.
+	v4s, v6s := p.families()
+	families := []struct {
+		suffix, setType, proto string
+		prefixes               []netip.Prefix
+	}{
+		{"v4", "ipv4_addr", "ip", network.Aggregate(v4s)},
+		{"v6", "ipv6_addr", "ip6", network.Aggregate(v6s)},
+	}
+
+	ew := &errWriter{w: w}
+	ew.printf("table inet %s {\n", p.Name)
+	for _, fam := range families {
+		if len(fam.prefixes) == 0 {
+			continue
+		}
+		elems := make([]string, len(fam.prefixes))
+		for i, pfx := range fam.prefixes {
+			elems[i] = pfx.String()
+		}
+		ew.printf("\tset %s_%s {\n", p.Name, fam.suffix)
+		ew.printf("\t\ttype %s\n", fam.setType)
+		ew.printf("\t\tflags interval\n")
+		ew.printf("\t\telements = { %s }\n", strings.Join(elems, ", "))
+		ew.printf("\t}\n\n")
+	}
+	ew.printf("\tchain %s {\n", hook)
+	ew.printf("\t\ttype filter hook %s priority filter;\n", hook)
+	for _, fam := range families {
+		if len(fam.prefixes) == 0 {
+			continue
+		}
+		ew.printf("\t\t")
+		if p.Interface != "" {
+			ew.printf("%s %q ", ifKey, p.Interface)
+		}
+		ew.printf("%s %s @%s_%s %s\n", fam.proto, addrKey, p.Name, fam.suffix, verdict)
+	}
+	ew.printf("\t}\n}\n")
+	return ew.err
+}
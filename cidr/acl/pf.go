@@ -0,0 +1,43 @@
+package acl
+
+import (
+	"io"
+	"strings"
+)
+
+// pf renders a persistent table holding both families and one quick rule per family.
+type pf struct{}
+
+func (pf) Render(w io.Writer, p Policy) error {
+	action := "pass"
+	if p.Action == Deny {
+		action = "block"
+	}
+	on := ""
+	if p.Interface != "" {
+		on = " on " + p.Interface
+	}
+
+	v4s, v6s := p.families()
+	elems := make([]string, 0, len(v4s)+len(v6s))
+	for _, pfx := range append(v4s, v6s...) {
+		elems = append(elems, pfx.String())
+	}
+
+	ew := &errWriter{w: w}
+	ew.printf("table <%s> persist { %s }\n", p.Name, strings.Join(elems, ", "))
+	for _, fam := range []struct {
+		af string
+		n  int
+	}{{"inet", len(v4s)}, {"inet6", len(v6s)}} {
+		if fam.n == 0 {
+			continue
+		}
+		if p.field() == Destination {
+			ew.printf("%s %s quick%s %s from any to <%s>\n", action, p.Direction, on, fam.af, p.Name)
+		} else {
+			ew.printf("%s %s quick%s %s from <%s> to any\n", action, p.Direction, on, fam.af, p.Name)
+		}
+	}
+	return ew.err
+}
@@ -0,0 +1,187 @@
+// Package addrmap draws how the address space of a parent prefix is used,
+// as a proportional block diagram of its allocated, reserved and free blocks.
+package addrmap
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"slices"
+)
+
+// Status is how a block of the parent prefix is used.
+type Status string
+
+const (
+	Allocated Status = "allocated"
+	Reserved  Status = "reserved"
+	Free      Status = "free"
+)
+
+// Block is a named part of the parent prefix.
+type Block struct {
+	Name   string
+	Prefix netip.Prefix
+	Status Status
+}
+
+// Map is a parent prefix and the blocks covering it, sorted by address.
+type Map struct {
+	Parent netip.Prefix
+	Blocks []Block
+}
+
+// New maps parent from the allocated blocks and the free prefixes, such as the
+// allocations and leftover subnets returned by network.VLSM. Space covered by
+// neither is reserved. Blocks must lie within parent and must not overlap.
+func New(parent netip.Prefix, allocated []Block, free []netip.Prefix) (Map, error) {
+	parent = parent.Masked()
+	blocks := make([]Block, 0, len(allocated)+len(free))
+	for _, b := range allocated {
+		if b.Status == "" {
+			b.Status = Allocated
+		}
+		blocks = append(blocks, b)
+	}
+	for _, p := range free {
+		blocks = append(blocks, Block{Prefix: p, Status: Free})
+	}
+	for i, b := range blocks {
+		blocks[i].Prefix = b.Prefix.Masked()
+		if !parent.Contains(b.Prefix.Addr()) || b.Prefix.Bits() < parent.Bits() {
+			return Map{}, fmt.Errorf("%s is not within %s", b.Prefix, parent)
+		}
+	}
+	slices.SortFunc(blocks, func(a, b Block) int {
+		return a.Prefix.Addr().Compare(b.Prefix.Addr())
+	})
+
+	// Walk the parent in address order, filling the gaps between blocks with reserved space.
+	m := Map{Parent: parent}
+	next := offset(parent, parent.Addr())
+	for i, b := range blocks {
+		start := offset(parent, b.Prefix.Addr())
+		if start.Cmp(next) < 0 {
+			return Map{}, fmt.Errorf("%s overlaps %s", b.Prefix, blocks[i-1].Prefix)
+		}
+		m.Blocks = append(m.Blocks, gap(parent, next, start)...)
+		m.Blocks = append(m.Blocks, b)
+		next = start.Add(start, size(b.Prefix))
+	}
+	m.Blocks = append(m.Blocks, gap(parent, next, size(parent))...)
+	return m, nil
+}
+
+// gap returns the space between the offsets from and to as reserved blocks.
+func gap(parent netip.Prefix, from, to *big.Int) []Block {
+	if from.Cmp(to) >= 0 {
+		return nil
+	}
+	var blocks []Block
+	for from.Cmp(to) < 0 {
+		// The largest block aligned at from that ends at or before to.
+		bits := parent.Addr().BitLen()
+		for bits > parent.Bits() {
+			n := new(big.Int).Lsh(big.NewInt(1), uint(parent.Addr().BitLen()-bits+1))
+			if new(big.Int).Mod(from, n).Sign() != 0 || new(big.Int).Add(from, n).Cmp(to) > 0 {
+				break
+			}
+			bits--
+		}
+		p := netip.PrefixFrom(addrAt(parent, from), bits)
+		blocks = append(blocks, Block{Prefix: p, Status: Reserved})
+		from = new(big.Int).Add(from, size(p))
+	}
+	return blocks
+}
+
+// Share returns the part of the parent that b covers, from 0 to 1.
+func (m Map) Share(b Block) float64 {
+	f, _ := new(big.Rat).SetFrac(size(b.Prefix), size(m.Parent)).Float64()
+	return f
+}
+
+// Stats summarises how much of a map is used and how fragmented its free space is.
+type Stats struct {
+	Allocated, Reserved, Free *big.Int
+	// FreeRanges is the number of contiguous runs of free space.
+	FreeRanges int
+	// LargestFree is the largest free block, invalid if there is no free space.
+	LargestFree netip.Prefix
+}
+
+// Stats counts the addresses of each status in m.
+func (m Map) Stats() Stats {
+	s := Stats{Allocated: new(big.Int), Reserved: new(big.Int), Free: new(big.Int)}
+	prevFree := false
+	for _, b := range m.Blocks {
+		switch b.Status {
+		case Allocated:
+			s.Allocated.Add(s.Allocated, size(b.Prefix))
+		case Reserved:
+			s.Reserved.Add(s.Reserved, size(b.Prefix))
+		case Free:
+			s.Free.Add(s.Free, size(b.Prefix))
+			if !prevFree {
+				s.FreeRanges++
+			}
+			if !s.LargestFree.IsValid() || b.Prefix.Bits() < s.LargestFree.Bits() {
+				s.LargestFree = b.Prefix
+			}
+		}
+		prevFree = b.Status == Free
+	}
+	return s
+}
+
+// segment is a run of the diagram: one allocated block, or adjacent reserved or free blocks.
+type segment struct {
+	block         Block // the first block of the run
+	index         int   // the index of the allocated block, counting from 0
+	offset, count *big.Int
+}
+
+// segments merges adjacent reserved and free blocks, which are drawn as one run.
+func (m Map) segments() []segment {
+	var segs []segment
+	allocated := 0
+	for _, b := range m.Blocks {
+		o := offset(m.Parent, b.Prefix.Addr())
+		if n := len(segs); n > 0 && b.Status != Allocated && segs[n-1].block.Status == b.Status {
+			segs[n-1].count.Add(segs[n-1].count, size(b.Prefix))
+			continue
+		}
+		s := segment{block: b, index: -1, offset: o, count: size(b.Prefix)}
+		if b.Status == Allocated {
+			s.index = allocated
+			allocated++
+		}
+		segs = append(segs, s)
+	}
+	return segs
+}
+
+// key returns the short label of the i-th allocated block.
+func key(i int) string {
+	const keys = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	if i < len(keys) {
+		return keys[i : i+1]
+	}
+	return fmt.Sprint(i + 1)
+}
+
+func size(p netip.Prefix) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits()))
+}
+
+func offset(parent netip.Prefix, a netip.Addr) *big.Int {
+	x := new(big.Int).SetBytes(a.AsSlice())
+	return x.Sub(x, new(big.Int).SetBytes(parent.Addr().AsSlice()))
+}
+
+func addrAt(parent netip.Prefix, o *big.Int) netip.Addr {
+	x := new(big.Int).Add(new(big.Int).SetBytes(parent.Addr().AsSlice()), o)
+	b := make([]byte, parent.Addr().BitLen()/8)
+	a, _ := netip.AddrFromSlice(x.FillBytes(b))
+	return a
+}
@@ -0,0 +1,161 @@
+package addrmap
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/go-learning-projects/cidr/output"
+)
+
+func testMap(t *testing.T) Map {
+	t.Helper()
+	m, err := New(netip.MustParsePrefix("10.0.0.0/22"),
+		[]Block{
+			{Name: "db", Prefix: netip.MustParsePrefix("10.0.2.0/25")},
+			{Name: "web", Prefix: netip.MustParsePrefix("10.0.0.0/24")},
+		},
+		[]netip.Prefix{netip.MustParsePrefix("10.0.1.0/24"), netip.MustParsePrefix("10.0.2.128/25")},
+	)
+	if err != nil {
+		t.Fatalf(`New returned error: %s`, err)
+	}
+	return m
+}
+
+// Blocks may be given in any order; the prefixes passed as free are free
+// and the rest of the parent is reserved.
+func TestNew(t *testing.T) {
+	// act
+	m := testMap(t)
+
+	// assert
+	want := []Block{
+		{Name: "web", Prefix: netip.MustParsePrefix("10.0.0.0/24"), Status: Allocated},
+		{Prefix: netip.MustParsePrefix("10.0.1.0/24"), Status: Free},
+		{Name: "db", Prefix: netip.MustParsePrefix("10.0.2.0/25"), Status: Allocated},
+		{Prefix: netip.MustParsePrefix("10.0.2.128/25"), Status: Free},
+		{Prefix: netip.MustParsePrefix("10.0.3.0/24"), Status: Reserved},
+	}
+	if len(m.Blocks) != len(want) {
+		t.Fatalf(`New returned %d blocks, want %d: %v`, len(m.Blocks), len(want), m.Blocks)
+	}
+	for i := range want {
+		if m.Blocks[i] != want[i] {
+			t.Errorf(`Blocks[%d] = %+v, want %+v`, i, m.Blocks[i], want[i])
+		}
+	}
+}
+
+// Blocks outside the parent or overlapping each other cannot be drawn.
+func TestNewInvalid(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/24")
+	tests := []struct {
+		name   string
+		blocks []string
+	}{
+		{"outside", []string{"10.0.1.0/25"}},
+		{"larger than parent", []string{"10.0.0.0/23"}},
+		{"overlapping", []string{"10.0.0.0/25", "10.0.0.64/26"}},
+		{"other family", []string{"2001:db8::/64"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var blocks []Block
+			for _, b := range tt.blocks {
+				blocks = append(blocks, Block{Prefix: netip.MustParsePrefix(b)})
+			}
+			_, err := New(parent, blocks, nil)
+			if err == nil {
+				t.Errorf(`New(%s, %v) returned no error`, parent, tt.blocks)
+			}
+		})
+	}
+}
+
+// The two free blocks are not adjacent, so they count as two free ranges.
+func TestStats(t *testing.T) {
+	// act
+	s := testMap(t).Stats()
+
+	// assert
+	if s.Allocated.Int64() != 384 || s.Reserved.Int64() != 256 || s.Free.Int64() != 384 {
+		t.Errorf(`Stats = %s allocated, %s reserved, %s free, want 384, 256, 384`, s.Allocated, s.Reserved, s.Free)
+	}
+	if s.FreeRanges != 2 {
+		t.Errorf(`FreeRanges = %d, want 2`, s.FreeRanges)
+	}
+	if want := netip.MustParsePrefix("10.0.1.0/24"); s.LargestFree != want {
+		t.Errorf(`LargestFree = %s, want %s`, s.LargestFree, want)
+	}
+}
+
+// The whole ASCII diagram, with colours off so it can be compared as text.
+func TestText(t *testing.T) {
+	// arrange
+	defer output.SetColor(output.ColorEnabled())
+	output.SetColor(false)
+	want := `10.0.0.0/22  1024 addresses
+####....==..xxxx
+A       B
+
+## A   10.0.0.0/24    web  allocated   25.00%
+..     10.0.1.0/24         free        25.00%
+== B   10.0.2.0/25    db   allocated   12.50%
+..     10.0.2.128/25       free        12.50%
+xx     10.0.3.0/24         reserved    25.00%
+
+Allocated 37.50%, reserved 25.00%, free 37.50% in 2 ranges, largest free block 10.0.1.0/24
+`
+
+	// act
+	got := testMap(t).Text(16, true)
+
+	// assert
+	if got != want {
+		t.Errorf("Text =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// Blocks smaller than a character still get one, so none disappear from the bar.
+func TestTextSmallBlocks(t *testing.T) {
+	// arrange
+	defer output.SetColor(output.ColorEnabled())
+	output.SetColor(false)
+	m, err := New(netip.MustParsePrefix("10.0.0.0/16"),
+		[]Block{{Prefix: netip.MustParsePrefix("10.0.0.0/30")}, {Prefix: netip.MustParsePrefix("10.0.0.4/30")}}, nil)
+	if err != nil {
+		t.Fatalf(`New returned error: %s`, err)
+	}
+
+	// act
+	bar := strings.Split(m.Text(8, true), "\n")[1]
+
+	// assert
+	// Blocks too small for a character still get one.
+	if want := "#=xxxxxx"; bar != want {
+		t.Errorf(`bar = %q, want %q`, bar, want)
+	}
+}
+
+// Block names are escaped, and every block has a rectangle in the bar and one in the legend.
+func TestSVG(t *testing.T) {
+	// arrange
+	m := testMap(t)
+	m.Blocks[0].Name = "<web>"
+
+	// act
+	got := m.SVG()
+
+	// assert
+	if !strings.HasPrefix(got, "<svg ") || !strings.HasSuffix(got, "</svg>\n") {
+		t.Errorf(`SVG is not a single svg element: %s`, got)
+	}
+	// One rectangle in the bar and one in the legend per block.
+	if n := strings.Count(got, "<rect "); n != 2*len(m.Blocks) {
+		t.Errorf(`SVG has %d rectangles, want %d`, n, 2*len(m.Blocks))
+	}
+	if strings.Contains(got, "<web>") {
+		t.Errorf(`SVG does not escape block names`)
+	}
+}
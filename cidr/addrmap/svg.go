@@ -0,0 +1,105 @@
+package addrmap
+
+import (
+	"fmt"
+	"html"
+	"math/big"
+	"strings"
+)
+
+// svgColors fill allocated blocks in turn.
+var svgColors = []string{"#4e79a7", "#59a14f", "#edc948", "#b07aa1", "#76b7b2", "#f28e2b"}
+
+const (
+	svgReserved = "#e15759"
+	svgFree     = "#eeeeee"
+
+	svgWidth  = 960.0
+	svgBar    = 48.0
+	svgMargin = 16.0
+	svgTitle  = 32.0
+	svgRow    = 20.0
+)
+
+// SVG draws m as a standalone SVG image: a bar with a rectangle per block,
+// each labelled if it is wide enough and titled with its details, and a legend below.
+func (m Map) SVG() string {
+	var b strings.Builder
+	rows := len(m.Blocks) + 1
+	height := svgMargin + svgTitle + svgBar + svgMargin + float64(rows)*svgRow + svgMargin
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif" font-size="12">`+"\n",
+		svgWidth+2*svgMargin, height, svgWidth+2*svgMargin, height)
+	fmt.Fprintf(&b, `<text x="%g" y="%g" font-size="16">%s (%s addresses)</text>`+"\n",
+		svgMargin, svgMargin+svgTitle/2, m.Parent, size(m.Parent))
+
+	total, _ := new(big.Float).SetInt(size(m.Parent)).Float64()
+	x := func(o *big.Int) float64 {
+		f, _ := new(big.Float).SetInt(o).Float64()
+		return svgMargin + f/total*svgWidth
+	}
+	top := svgMargin + svgTitle
+	allocated := 0
+	for _, bl := range m.Blocks {
+		o := offset(m.Parent, bl.Prefix.Addr())
+		left, right := x(o), x(new(big.Int).Add(o, size(bl.Prefix)))
+		// Blocks too small to see are still drawn a pixel wide.
+		w := max(right-left, 1)
+		fill, label := svgFree, ""
+		switch bl.Status {
+		case Allocated:
+			fill, label = svgColors[allocated%len(svgColors)], key(allocated)
+			allocated++
+		case Reserved:
+			fill = svgReserved
+		}
+		fmt.Fprintf(&b, `<rect x="%.2f" y="%g" width="%.2f" height="%g" fill="%s" stroke="#ffffff"><title>%s</title></rect>`+"\n",
+			left, top, w, svgBar, fill, html.EscapeString(m.describe(bl)))
+		if label != "" && w >= 24 {
+			fmt.Fprintf(&b, `<text x="%.2f" y="%g" text-anchor="middle">%s</text>`+"\n",
+				left+w/2, top+svgBar/2+4, label)
+		}
+	}
+
+	y := top + svgBar + svgMargin
+	allocated = 0
+	for _, bl := range m.Blocks {
+		fill, k := svgFree, ""
+		switch bl.Status {
+		case Allocated:
+			fill, k = svgColors[allocated%len(svgColors)], key(allocated)+" "
+			allocated++
+		case Reserved:
+			fill = svgReserved
+		}
+		fmt.Fprintf(&b, `<rect x="%g" y="%g" width="14" height="14" fill="%s" stroke="#999999"/>`+"\n", svgMargin, y, fill)
+		fmt.Fprintf(&b, `<text x="%g" y="%g">%s</text>`+"\n", svgMargin+22, y+11, html.EscapeString(k+m.describe(bl)))
+		y += svgRow
+	}
+	fmt.Fprintf(&b, `<text x="%g" y="%g">%s</text>`+"\n", svgMargin, y+11, html.EscapeString(m.Stats().String()))
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// HTML wraps the SVG diagram of m in a standalone HTML page.
+func (m Map) HTML() string {
+	title := html.EscapeString(m.Parent.String())
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body>
+%s</body>
+</html>
+`, title, m.SVG())
+}
+
+// describe returns the prefix, name, status and share of b.
+func (m Map) describe(b Block) string {
+	name := ""
+	if b.Name != "" {
+		name = " " + b.Name
+	}
+	return fmt.Sprintf("%s%s, %s, %.2f%%", b.Prefix, name, b.Status, 100*m.Share(b))
+}
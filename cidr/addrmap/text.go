@@ -0,0 +1,139 @@
+package addrmap
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/output"
+)
+
+// glyphs fill the bar of a text diagram. Allocated blocks alternate
+// between two glyphs, so neighbours stay apart without colours.
+type glyphs struct {
+	allocated [2]string
+	reserved  string
+	free      string
+}
+
+var (
+	unicodeGlyphs = glyphs{allocated: [2]string{"█", "▓"}, reserved: "▒", free: "░"}
+	asciiGlyphs   = glyphs{allocated: [2]string{"#", "="}, reserved: "x", free: "."}
+)
+
+// textColors colour allocated blocks in turn when colours are on.
+var textColors = []fmt.Stringer{output.Blue, output.Green, output.Yellow, output.Magenta, output.Cyan}
+
+// Text draws m as a bar width characters wide, with the key of every allocated block
+// below it, followed by a legend and a summary. Every run gets at least one character,
+// so a map with many small blocks can be wider than width. With ascii set, only ASCII
+// characters are used.
+func (m Map) Text(width int, ascii bool) string {
+	g := unicodeGlyphs
+	if ascii {
+		g = asciiGlyphs
+	}
+
+	var bar, keys strings.Builder
+	total := size(m.Parent)
+	end := 0
+	for _, s := range m.segments() {
+		from := cell(s.offset, total, width)
+		to := cell(new(big.Int).Add(s.offset, s.count), total, width)
+		from = max(from, end)
+		to = max(to, from+1)
+		end = to
+
+		glyph, k := g.free, ""
+		switch s.block.Status {
+		case Allocated:
+			glyph, k = g.allocated[s.index%2], key(s.index)
+		case Reserved:
+			glyph = g.reserved
+		}
+		bar.WriteString(paint(colorOf(s), strings.Repeat(glyph, to-from)))
+		// Keys longer than their run are cut, so the keys after them stay in place.
+		keys.WriteString((k + strings.Repeat(" ", to-from))[:to-from])
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s  %s addresses\n", m.Parent, total)
+	b.WriteString(bar.String() + "\n")
+	b.WriteString(strings.TrimRight(keys.String(), " ") + "\n\n")
+
+	prefixWidth, nameWidth := 0, 0
+	for _, bl := range m.Blocks {
+		prefixWidth = max(prefixWidth, len(bl.Prefix.String()))
+		// Names get a column only if any block has one.
+		if bl.Name != "" {
+			nameWidth = max(nameWidth, len(bl.Name)+2)
+		}
+	}
+	allocated := 0
+	for _, bl := range m.Blocks {
+		k, glyph, c := "", g.free, ""
+		switch bl.Status {
+		case Allocated:
+			k, glyph = key(allocated), g.allocated[allocated%2]
+			c = textColors[allocated%len(textColors)].String()
+			allocated++
+		case Reserved:
+			glyph, c = g.reserved, output.Red.String()
+		}
+		fmt.Fprintf(&b, "%s %-3s %-*s  %-*s%-9s  %6.2f%%\n",
+			paint(c, strings.Repeat(glyph, 2)), k, prefixWidth, bl.Prefix, nameWidth, bl.Name, bl.Status, 100*m.Share(bl))
+	}
+
+	b.WriteString("\n" + m.Stats().String() + "\n")
+	return b.String()
+}
+
+// String summarises s as shares of the whole map.
+func (s Stats) String() string {
+	total := new(big.Int).Add(s.Allocated, s.Reserved)
+	total.Add(total, s.Free)
+	share := func(n *big.Int) float64 {
+		f, _ := new(big.Rat).SetFrac(n, total).Float64()
+		return 100 * f
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Allocated %.2f%%", share(s.Allocated))
+	if s.Reserved.Sign() > 0 {
+		fmt.Fprintf(&b, ", reserved %.2f%%", share(s.Reserved))
+	}
+	fmt.Fprintf(&b, ", free %.2f%%", share(s.Free))
+	if s.FreeRanges > 0 {
+		ranges := "range"
+		if s.FreeRanges > 1 {
+			ranges += "s"
+		}
+		fmt.Fprintf(&b, " in %d %s, largest free block %s", s.FreeRanges, ranges, s.LargestFree)
+	}
+	return b.String()
+}
+
+// colorOf returns the colour a run is drawn in.
+func colorOf(s segment) string {
+	switch s.block.Status {
+	case Allocated:
+		return textColors[s.index%len(textColors)].String()
+	case Reserved:
+		return output.Red.String()
+	}
+	return ""
+}
+
+// paint writes s in colour c, if c is set.
+func paint(c, s string) string {
+	if c == "" {
+		return s
+	}
+	return c + s + output.Reset.String()
+}
+
+// cell returns the character that offset o of total falls in, on a bar width characters wide.
+func cell(o, total *big.Int, width int) int {
+	x := new(big.Int).Mul(o, big.NewInt(int64(width)))
+	return int(x.Quo(x, total).Int64())
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/acl"
+	"github.com/spf13/cobra"
+)
+
+var (
+	aclSyntax    string
+	aclAction    string
+	aclDirection string
+	aclMatch     string
+	aclName      string
+	aclInterface string
+)
+
+func init() {
+	rootCmd.AddCommand(aclCmd)
+	aclCmd.Flags().StringVarP(&aclSyntax, "syntax", "s", "", fmt.Sprintf("Firewall syntax (%s)", strings.Join(acl.Syntaxes(), ", ")))
+	aclCmd.Flags().StringVarP(&aclAction, "action", "a", string(acl.Allow), "What to do with matching traffic (allow, deny)")
+	aclCmd.Flags().StringVarP(&aclDirection, "direction", "d", string(acl.In), "Direction of the filtered traffic (in, out)")
+	aclCmd.Flags().StringVarP(&aclMatch, "match", "m", "", "Address to match (source, destination); defaults to source for in and destination for out")
+	aclCmd.Flags().StringVarP(&aclName, "name", "n", "cidr", "Name of the generated set, table, ACL or filter")
+	aclCmd.Flags().StringVarP(&aclInterface, "interface", "i", "", "Restrict the rules to an interface")
+	addInputFlags(aclCmd, "CIDRs")
+	if err := aclCmd.MarkFlagRequired("syntax"); err != nil {
+		os.Exit(1)
+	}
+}
+
+var aclCmd = &cobra.Command{
+	Use:   "acl",
+	Short: "Generate firewall rules for a list of CIDRs",
+	Long: `ACL turns a list of CIDRs into firewall rules for iptables, nftables, pf, Cisco IOS or Junos.
+v4 and v6 networks may be mixed; each family is written to the tables, lists or commands the firewall uses for it:
+  iptables  one command per network, with ip6tables for v6
+  nftables  an inet table with a named interval set per family (networks are merged, as sets reject overlaps)
+  pf        a persistent table and one rule per family
+  cisco     an extended ACL with wildcard masks for v4 and an IPv6 access list
+  juniper   "set" commands for a firewall filter per family
+CIDRs are read from the arguments, from files given with --file, or from stdin.
+ACL takes no -o: the rules are already written in the syntax of the firewall.`,
+	Example: `cidr acl --syntax nftables 10.0.0.0/8 2001:db8::/32
+cidr acl -s cisco --action deny --name BLOCKED -f blocklist.txt
+cidr acl -s iptables --direction out --interface eth0 192.0.2.0/24`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := acl.GetRenderer(aclSyntax)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		prefixes := readPrefixes(cmd, args, inputFiles)
+		if len(prefixes) < 1 {
+			cmd.PrintErrln("Usage: cidr acl --syntax <syntax> <CIDR1> <CIDR2> ...")
+			os.Exit(1)
+		}
+
+		p := acl.Policy{
+			Name:      aclName,
+			Action:    acl.Action(strings.ToLower(aclAction)),
+			Direction: acl.Direction(strings.ToLower(aclDirection)),
+			Match:     acl.Field(strings.ToLower(aclMatch)),
+			Interface: aclInterface,
+			Prefixes:  prefixes,
+		}
+		out := bufio.NewWriter(cmd.OutOrStdout())
+		err = acl.Render(out, r, p)
+		if err == nil {
+			err = out.Flush()
+		}
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import "testing"
+
+// alloc --hosts reserves the network and last address in v6 pools too, like vlsm.
+func TestAllocBits(t *testing.T) {
+	tests := []struct {
+		hosts    int
+		addrBits int
+		want     int
+	}{
+		{2, 32, 30},
+		{2, 128, 126},
+		{100, 32, 25},
+		{254, 128, 120},
+	}
+	defer func(h int) { allocHosts = h }(allocHosts)
+	for _, tt := range tests {
+		allocHosts = tt.hosts
+		got, err := allocBits(tt.addrBits)
+		if err != nil || got != tt.want {
+			t.Errorf(`allocBits(%d) with %d hosts = %d, %v, want %d`, tt.addrBits, tt.hosts, got, err, tt.want)
+		}
+	}
+}
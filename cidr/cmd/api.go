@@ -0,0 +1,375 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+)
+
+// maxRequestBytes limits the size of a request body.
+const maxRequestBytes = 1 << 20
+
+// apiMaxRows limits the subnets a divide or vlsm request may list in its response,
+// so that a single request cannot exhaust the memory of the server.
+const apiMaxRows = network.MaxSubnets
+
+// apiError is the body of every error response.
+type apiError struct {
+	Error   string       `json:"error"`
+	Details []inputError `json:"details,omitempty"`
+}
+
+// inputError reports an entry of a request that could not be used.
+type inputError struct {
+	Input string `json:"input"`
+	Error string `json:"error"`
+}
+
+// apiFailure is an error with the status and body it is reported with.
+type apiFailure struct {
+	status int
+	body   apiError
+}
+
+func (f *apiFailure) Error() string {
+	return f.body.Error
+}
+
+func badRequest(msg string, details ...inputError) error {
+	return &apiFailure{status: http.StatusBadRequest, body: apiError{Error: msg, Details: details}}
+}
+
+// unprocessable reports a valid request that cannot be carried out, such as a plan that does not fit.
+func unprocessable(err error, details ...inputError) error {
+	return &apiFailure{status: http.StatusUnprocessableEntity, body: apiError{Error: err.Error(), Details: details}}
+}
+
+// apiEndpoint is a POST endpoint taking and returning JSON.
+type apiEndpoint struct {
+	path        string
+	summary     string
+	description string
+	request     reflect.Type
+	responses   []reflect.Type
+	handle      func(body io.Reader) (any, error)
+}
+
+func post[Req, Resp any](path, summary, description string, fn func(Req) (Resp, error)) apiEndpoint {
+	return apiEndpoint{
+		path:        path,
+		summary:     summary,
+		description: description,
+		request:     reflect.TypeFor[Req](),
+		responses:   []reflect.Type{reflect.TypeFor[Resp]()},
+		handle: func(body io.Reader) (any, error) {
+			var req Req
+			if err := decodeBody(body, &req); err != nil {
+				return nil, err
+			}
+			return fn(req)
+		},
+	}
+}
+
+// decodeBody decodes a body holding a single JSON object into v.
+// Bodies over maxRequestBytes are reported with status 413.
+func decodeBody(body io.Reader, v any) error {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(v)
+	if err == nil {
+		// Anything after the object is rejected rather than ignored.
+		if err = dec.Decode(&struct{}{}); errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err == nil {
+			err = errors.New("unexpected data after the JSON object")
+		}
+	}
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &apiFailure{status: http.StatusRequestEntityTooLarge,
+			body: apiError{Error: fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit)}}
+	}
+	return badRequest(fmt.Sprintf("invalid request body: %s", err))
+}
+
+// networksRequest lists the networks to explain, count or merge.
+type networksRequest struct {
+	Networks []string `json:"networks"`
+}
+
+type containsRequest struct {
+	Network   string   `json:"network"`
+	Addresses []string `json:"addresses"`
+}
+
+type divideRequest struct {
+	Networks []string `json:"networks"`
+	Count    int      `json:"count"`
+	VLSM     bool     `json:"vlsm,omitempty"`
+	Nibble   bool     `json:"nibble,omitempty"`
+}
+
+// vlsmAPIRequest takes the requests in the notation of the vlsm command, e.g. "web=120" or "lan=12x/64".
+type vlsmAPIRequest struct {
+	Network    string   `json:"network"`
+	Requests   []string `json:"requests"`
+	Reserve    []string `json:"reserve,omitempty"`
+	InputOrder bool     `json:"inputOrder,omitempty"`
+	Nibble     bool     `json:"nibble,omitempty"`
+}
+
+type embedRequest struct {
+	Prefix    string   `json:"prefix"`
+	Addresses []string `json:"addresses"`
+}
+
+// apiEndpoints are the endpoints served by serve, in the order they are documented.
+var apiEndpoints = []apiEndpoint{
+	post("/v1/explain", "Explain networks", "Returns one explanation per network, as explain -o json does for several networks.", apiExplain),
+	post("/v1/count", "Count addresses", "Returns the number of addresses in each network.", apiCount),
+	post("/v1/contains", "Check addresses against a network", "Reports for each address whether the network contains it.", apiContains),
+	post("/v1/divide", "Divide networks into subnets", "Divides every network into count subnets, as divide does. At most 1048576 subnets are listed in total.", apiDivide),
+	vlsmEndpoint(),
+	post("/v1/embed", "Embed v4 addresses in a v6 prefix", "Embeds each v4 address in the v6 prefix as described in RFC 6052.", apiEmbed),
+	post("/v1/merge", "Merge networks", "Returns the smallest set of prefixes covering the same addresses.", apiMerge),
+}
+
+func vlsmEndpoint() apiEndpoint {
+	e := post("/v1/vlsm", "Plan subnets with VLSM",
+		"Allocates a subnet per request. Requests by host count return vlsm rows, requests by subnet count return block rows. "+
+			"At most 1048576 subnets are listed in total.", apiVLSM)
+	e.responses = []reflect.Type{reflect.TypeFor[[]vlsmOutput](), reflect.TypeFor[[]vlsmBlockOutput]()}
+	return e
+}
+
+// newAPIHandler returns the handler of the JSON API.
+func newAPIHandler() http.Handler {
+	mux := http.NewServeMux()
+	for _, e := range apiEndpoints {
+		mux.Handle(e.path, e)
+	}
+	mux.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeMethodNotAllowed(w, http.MethodGet)
+			return
+		}
+		writeJSON(w, http.StatusOK, openAPIDocument(apiEndpoints))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, apiError{Error: fmt.Sprintf("no endpoint at %s", r.URL.Path)})
+	})
+	return mux
+}
+
+func (e apiEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w, http.MethodPost)
+		return
+	}
+	result, err := e.handle(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		var f *apiFailure
+		if !errors.As(err, &f) {
+			f = &apiFailure{status: http.StatusInternalServerError, body: apiError{Error: err.Error()}}
+		}
+		writeJSON(w, f.status, f.body)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeJSON(w, http.StatusMethodNotAllowed, apiError{Error: "method not allowed, use " + allow})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// parseAll parses every input, reporting all that fail rather than the first.
+func parseAll[T any](what string, inputs []string, parse func(string) (T, error)) ([]T, error) {
+	if len(inputs) == 0 {
+		return nil, badRequest(fmt.Sprintf("no %s given", what))
+	}
+	values := make([]T, 0, len(inputs))
+	var details []inputError
+	for _, in := range inputs {
+		v, err := parse(in)
+		if err != nil {
+			details = append(details, inputError{Input: in, Error: err.Error()})
+			continue
+		}
+		values = append(values, v)
+	}
+	if len(details) > 0 {
+		return nil, badRequest(fmt.Sprintf("invalid %s", what), details...)
+	}
+	return values, nil
+}
+
+func apiExplain(r networksRequest) ([]network.Explanation, error) {
+	networks, err := parseAll("networks", r.Networks, newNetwork)
+	if err != nil {
+		return nil, err
+	}
+	o := make([]network.Explanation, len(networks))
+	for i, n := range networks {
+		o[i] = network.Explain(n)
+	}
+	return o, nil
+}
+
+func apiCount(r networksRequest) ([]countOutput, error) {
+	networks, err := parseAll("networks", r.Networks, newNetwork)
+	if err != nil {
+		return nil, err
+	}
+	o := make([]countOutput, len(networks))
+	for i, n := range networks {
+		o[i] = countOutput{Network: strings.TrimSpace(r.Networks[i]), Addresses: n.Count()}
+	}
+	return o, nil
+}
+
+func apiContains(r containsRequest) ([]containsOutput, error) {
+	n, err := newNetwork(r.Network)
+	if err != nil {
+		return nil, badRequest("invalid network", inputError{Input: r.Network, Error: err.Error()})
+	}
+	parse := addrParser(n)
+	addrs, err := parseAll("addresses", r.Addresses, func(s string) (string, error) {
+		a, err := parse(strings.TrimSpace(s))
+		return a.String(), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	contained := n.Contains(addrs)
+	o := make([]containsOutput, len(addrs))
+	for i, a := range addrs {
+		o[i] = containsOutput{Address: a, Network: r.Network, Contained: contained[a]}
+	}
+	return o, nil
+}
+
+func apiDivide(r divideRequest) ([]subnetOutput, error) {
+	if r.Count <= 0 {
+		return nil, badRequest("count must be > 0")
+	}
+	if r.VLSM && r.Nibble {
+		return nil, badRequest("nibble cannot be combined with vlsm")
+	}
+	if r.Count > apiMaxRows {
+		return nil, unprocessable(fmt.Errorf("count must be at most %d", apiMaxRows))
+	}
+	networks, err := parseAll("networks", r.Networks, newNetwork)
+	if err != nil {
+		return nil, err
+	}
+	if len(networks)*r.Count > apiMaxRows {
+		return nil, unprocessable(fmt.Errorf("%d networks of %d subnets each would list more than %d subnets", len(networks), r.Count, apiMaxRows))
+	}
+
+	var o []subnetOutput
+	var details []inputError
+	for i, n := range networks {
+		var subnets []netip.Prefix
+		if r.Nibble {
+			subnets, err = network.DivideNibble(n.Prefix(), r.Count)
+		} else {
+			subnets, err = n.Divide(r.Count, r.VLSM)
+		}
+		if err != nil {
+			details = append(details, inputError{Input: r.Networks[i], Error: err.Error()})
+			continue
+		}
+		for _, s := range subnets {
+			o = append(o, newSubnetOutput(n.Prefix().String(), s))
+		}
+	}
+	if len(details) > 0 {
+		return nil, unprocessable(errors.New("could not divide"), details...)
+	}
+	return o, nil
+}
+
+func apiVLSM(r vlsmAPIRequest) (any, error) {
+	p, err := parsePrefix(r.Network)
+	if err != nil {
+		return nil, badRequest("invalid network", inputError{Input: r.Network, Error: err.Error()})
+	}
+	reqs, err := parseAll("requests", r.Requests, parseVLSMRequest)
+	if err != nil {
+		return nil, err
+	}
+	listed := 0
+	for _, q := range reqs {
+		if q.subnets == nil {
+			continue
+		}
+		if listed += q.subnets.Count; q.subnets.Count > apiMaxRows || listed > apiMaxRows {
+			return nil, unprocessable(fmt.Errorf("requests would list more than %d subnets", apiMaxRows))
+		}
+	}
+	var reserved []netip.Prefix
+	if len(r.Reserve) > 0 {
+		sets, err := parseAll("reserved networks", r.Reserve, parsePrefixes)
+		if err != nil {
+			return nil, err
+		}
+		reserved = slices.Concat(sets...)
+	}
+
+	plan, err := planVLSM(p, reqs, network.VLSMOptions{Reserved: reserved, InputOrder: r.InputOrder, Nibble: r.Nibble})
+	if err != nil {
+		return nil, unprocessable(err)
+	}
+	return plan.rows(), nil
+}
+
+func apiEmbed(r embedRequest) ([]embedOutput, error) {
+	n, err := newNetwork(r.Prefix)
+	if err != nil {
+		return nil, badRequest("invalid prefix", inputError{Input: r.Prefix, Error: err.Error()})
+	}
+	if len(r.Addresses) == 0 {
+		return nil, badRequest("no addresses given")
+	}
+	o := make([]embedOutput, 0, len(r.Addresses))
+	var details []inputError
+	for _, a := range r.Addresses {
+		addr, err := n.Embed(strings.TrimSpace(a))
+		if err != nil {
+			details = append(details, inputError{Input: a, Error: err.Error()})
+			continue
+		}
+		o = append(o, embedOutput{IPv4: strings.TrimSpace(a), IPv6: addr.String()})
+	}
+	if len(details) > 0 {
+		return nil, badRequest("could not embed addresses", details...)
+	}
+	return o, nil
+}
+
+func apiMerge(r networksRequest) ([]prefixOutput, error) {
+	sets, err := parseAll("networks", r.Networks, parsePrefixes)
+	if err != nil {
+		return nil, err
+	}
+	return prefixOutputs(network.Aggregate(slices.Concat(sets...))), nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Every endpoint once, plus the request errors shared by all of them:
+// invalid input, unknown fields, trailing data, oversized bodies and results too large to list.
+func TestAPI(t *testing.T) {
+	srv := httptest.NewServer(newAPIHandler())
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+		want       string // a fragment of the compacted response
+	}{
+		{"explain", "POST", "/v1/explain", `{"networks": ["10.0.0.0/24"]}`, 200, `"baseAddress":"10.0.0.0"`},
+		{"count", "POST", "/v1/count", `{"networks": ["10.0.0.0/16", "2001:db8::/64"]}`, 200, `[{"network":"10.0.0.0/16","addresses":65536},{"network":"2001:db8::/64","addresses":18446744073709551616}]`},
+		{"contains", "POST", "/v1/contains", `{"network": "10.0.0.0/16", "addresses": ["10.0.3.7", "10.1.0.1"]}`, 200, `"contained":true},{"address":"10.1.0.1","network":"10.0.0.0/16","contained":false}`},
+		{"divide", "POST", "/v1/divide", `{"networks": ["10.0.0.0/24"], "count": 2}`, 200, `"subnet":"10.0.0.128/25"`},
+		{"vlsm by hosts", "POST", "/v1/vlsm", `{"network": "10.0.0.0/24", "requests": ["web=100", "db=20"]}`, 200, `"name":"db","subnet":"10.0.0.128/27","status":"allocated","baseAddress":"10.0.0.128","requestedHosts":20`},
+		{"vlsm by subnets", "POST", "/v1/vlsm", `{"network": "2001:db8::/48", "requests": ["lan=12x/64"], "nibble": true}`, 200, `"block":"2001:db8::/60","status":"allocated","requested":"12x/64","slash64s":16`},
+		{"embed", "POST", "/v1/embed", `{"prefix": "64:ff9b::/96", "addresses": ["192.0.2.33"]}`, 200, `[{"ipv4":"192.0.2.33","ipv6":"64:ff9b::c000:221"}]`},
+		{"merge", "POST", "/v1/merge", `{"networks": ["10.0.0.0/25", "10.0.0.128/25"]}`, 200, `[{"prefix":"10.0.0.0/24"`},
+		{"invalid entries", "POST", "/v1/count", `{"networks": ["10.0.0.0/16", "bad"]}`, 400, `"details":[{"input":"bad"`},
+		{"contains wrong family", "POST", "/v1/contains", `{"network": "10.0.0.0/16", "addresses": ["10.0.3.7", "::1"]}`, 400, `"details":[{"input":"::1","error":"::1 is not in the address family of 10.0.0.0/16"}]`},
+		{"unknown field", "POST", "/v1/merge", `{"cidrs": []}`, 400, `"error":"invalid request body`},
+		{"trailing data", "POST", "/v1/count", `{"networks": ["10.0.0.0/8"]} junk`, 400, `"error":"invalid request body: invalid character 'j'`},
+		{"second object", "POST", "/v1/count", `{"networks": ["10.0.0.0/8"]} {}`, 400, `"error":"invalid request body: unexpected data after the JSON object"`},
+		{"too large", "POST", "/v1/count", `{"networks": ["` + strings.Repeat("1", maxRequestBytes) + `"]}`, 413, `"error":"request body is larger than 1048576 bytes"`},
+		{"too large after object", "POST", "/v1/count", `{"networks": ["10.0.0.0/8"]}` + strings.Repeat(" ", maxRequestBytes), 413, `"error":"request body is larger than 1048576 bytes"`},
+		{"does not fit", "POST", "/v1/vlsm", `{"network": "10.0.0.0/24", "requests": ["300"]}`, 422, `"error":"insufficient address space for 300 hosts"`},
+		{"vlsm too many hosts", "POST", "/v1/vlsm", `{"network": "10.0.0.0/8", "requests": ["web=9223372036854775807"]}`, 422, `"error":"web: 9223372036854775807 hosts do not fit in any prefix, the most is 4294967294"`},
+		{"divide too many", "POST", "/v1/divide", `{"networks": ["2001:db8::/32"], "count": 1099511627776}`, 422, `"error":"count must be at most 1048576"`},
+		{"divide nibble too many", "POST", "/v1/divide", `{"networks": ["2001:db8::/32"], "count": 1099511627776, "nibble": true}`, 422, `"error":"count must be at most 1048576"`},
+		{"divide too many rows", "POST", "/v1/divide", `{"networks": ["2001:db8::/32", "2001:db9::/32"], "count": 1048576}`, 422, `"error":"2 networks of 1048576 subnets each would list more than 1048576 subnets"`},
+		{"vlsm too many", "POST", "/v1/vlsm", `{"network": "2001:db8::/32", "requests": ["lan=1099511627776x/72"]}`, 422, `"error":"requests would list more than 1048576 subnets"`},
+		{"vlsm too many rows", "POST", "/v1/vlsm", `{"network": "2001:db8::/32", "requests": ["lan=1048576x/72", "wan=1x/64"]}`, 422, `"error":"requests would list more than 1048576 subnets"`},
+		{"wrong method", "GET", "/v1/count", ``, 405, `"error":"method not allowed, use POST"`},
+		{"unknown path", "POST", "/v1/nope", `{}`, 404, `"error":"no endpoint at /v1/nope"`},
+		{"openapi", "GET", "/openapi.json", ``, 200, `"openapi":"3.0.3"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf(`status = %d, want %d`, resp.StatusCode, tt.wantStatus)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf(`Content-Type = %q, want application/json`, ct)
+			}
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, body); err != nil {
+				t.Fatalf(`response is not JSON: %s`, err)
+			}
+			if !strings.Contains(compact.String(), tt.want) {
+				t.Errorf(`response = %s, want it to contain %s`, compact.String(), tt.want)
+			}
+		})
+	}
+}
+
+// The document is generated from the endpoint table and the response types,
+// so every endpoint must end up with a path and every type with a schema.
+func TestOpenAPIDocument(t *testing.T) {
+	// act
+	doc := openAPIDocument(apiEndpoints)
+
+	// assert
+	paths := doc["paths"].(map[string]any)
+	for _, e := range apiEndpoints {
+		if _, ok := paths[e.path]; !ok {
+			t.Errorf(`OpenAPI document has no path %s`, e.path)
+		}
+	}
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	for _, name := range []string{"Explanation", "Count", "Contains", "Subnet", "Vlsm", "VlsmBlock", "Embed", "Prefix", "Error"} {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf(`OpenAPI document has no schema %s`, name)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+var complementWithin string
+
+func init() {
+	rootCmd.AddCommand(complementCmd)
+	complementCmd.Flags().StringVarP(&complementWithin, "within", "w", "", "Only consider addresses inside this set instead of the whole address space")
+	addOutputFlag(complementCmd, "")
+}
+
+var complementCmd = &cobra.Command{
+	Use:   "complement",
+	Short: "Print the addresses not covered by a set of networks",
+	Long: `Complement prints every address that is not covered by the given set of CIDRs
+as the smallest list of prefixes. Without --within, the complement is taken over the
+entire address space of each family present in the set.
+Each set is a comma separated list of CIDRs, a file with one CIDR per line, or "-" for stdin.`,
+	Aliases: []string{"not"},
+	Example: `cidr complement 10.0.0.0/8,172.16.0.0/12,192.168.0.0/16
+cidr complement used.txt --within 10.0.0.0/16`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr complement <set> [--within <set>]")
+			os.Exit(1)
+		}
+
+		set := readSet(cmd, args[0])
+		if complementWithin != "" {
+			printPrefixes(cmd, network.Exclude(readSet(cmd, complementWithin), set))
+			return
+		}
+		printPrefixes(cmd, network.Complement(set))
+	},
+}
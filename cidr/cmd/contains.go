@@ -3,37 +3,70 @@ package cmd
 import (
 	"os"
 
-	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(containsCmd)
+	addInputFlags(containsCmd, "addresses")
+	addOutputFlag(containsCmd, "")
+}
+
+type containsOutput struct {
+	Address   string `json:"address" tabs:"Address"`
+	Network   string `json:"network" tabs:"Network"`
+	Contained bool   `json:"contained" tabs:"Contained"`
 }
 
 var containsCmd = &cobra.Command{
 	Use:     "contains",
 	Short:   "Check if a network contains specific addresses",
 	Aliases: []string{"in"},
-	Example: "cidr contains 10.0.0.0/16 10.0.0.1 10.0.0.2",
+	Example: `cidr contains 10.0.0.0/16 10.0.0.1 10.0.0.2
+cidr contains 10.0.0.0/16 -f addresses.txt`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 2 {
+		if len(args) < 1 {
 			cmd.PrintErrln("Usage: cidr contains <CIDR> <IP1> <IP2> ...")
 			os.Exit(1)
 		}
 
-		n, err := network.New(args[0])
+		n, err := newNetwork(args[0])
 		if err != nil {
 			cmd.PrintErrf("Error: %s\n", err)
 			os.Exit(1)
 		}
-		r := n.Contains(args[1:])
-		for i, _ := range r {
-			if r[i] {
-				cmd.Printf("%s is contained in %s\n", i, args[0])
-			} else {
-				cmd.Printf("%s is NOT contained in %s\n", i, args[0])
+
+		lines := readLines(cmd, args[1:])
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr contains <CIDR> <IP1> <IP2> ...")
+			os.Exit(1)
+		}
+		_, parsed, ok := parseLines(cmd, lines, addrParser(n))
+
+		addrs := make([]string, len(parsed))
+		for i, l := range parsed {
+			addrs[i] = l.Text
+		}
+		r := n.Contains(addrs)
+		// Report in input order rather than map order.
+		if f, structured := outputFormatter(cmd); structured {
+			o := make([]containsOutput, len(addrs))
+			for i, a := range addrs {
+				o[i] = containsOutput{Address: a, Network: args[0], Contained: r[a]}
+			}
+			printResult(cmd, f, o)
+		} else {
+			for _, a := range addrs {
+				if r[a] {
+					cmd.Printf("%s is contained in %s\n", a, args[0])
+				} else {
+					cmd.Printf("%s is NOT contained in %s\n", a, args[0])
+				}
 			}
 		}
+		if !ok {
+			os.Exit(1)
+		}
 	},
 }
@@ -1,34 +1,51 @@
 package cmd
 
 import (
+	"math/big"
 	"os"
 
-	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(countCmd)
+	addInputFlags(countCmd, "CIDRs")
+	addOutputFlag(countCmd, "")
+}
+
+type countOutput struct {
+	Network   string   `json:"network" tabs:"Network"`
+	Addresses *big.Int `json:"addresses" tabs:"Addresses"`
 }
 
 var countCmd = &cobra.Command{
 	Use:     "count",
 	Short:   "Count addresses in a CIDR network",
 	Aliases: []string{"c", "num"},
-	Example: "cidr count 10.0.0.0/16",
+	Example: `cidr count 10.0.0.0/16
+cidr count -f networks.txt`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
 			cmd.PrintErrln("Usage: cidr count <CIDR>")
 			os.Exit(1)
 		}
 
-		for _, arg := range args {
-			n, err := network.New(arg)
-			if err != nil {
-				cmd.PrintErrf("Error: %s\n", err)
-				os.Exit(1)
+		networks, parsed, ok := parseLines(cmd, lines, newNetwork)
+		if f, structured := outputFormatter(cmd); structured {
+			o := make([]countOutput, len(networks))
+			for i, n := range networks {
+				o[i] = countOutput{Network: parsed[i].Text, Addresses: n.Count()}
+			}
+			printResult(cmd, f, o)
+		} else {
+			for _, n := range networks {
+				cmd.Println(n.Count())
 			}
-			cmd.Println(n.Count())
+		}
+		if !ok {
+			os.Exit(1)
 		}
 	},
 }
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"math/big"
+	"net/netip"
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.Flags().Bool("exit-code", false, "Exit with status 1 if the lists do not cover the same addresses")
+	addOutputFlag(diffCmd, "")
+}
+
+// diffOutput describes a prefix gained, lost or unchanged between two lists.
+type diffOutput struct {
+	Change    string   `json:"change" tabs:"Change"`
+	Prefix    string   `json:"prefix" tabs:"Prefix"`
+	First     string   `json:"first" tabs:"First"`
+	Last      string   `json:"last" tabs:"Last"`
+	Addresses *big.Int `json:"addresses" tabs:"Addresses"`
+}
+
+func diffOutputs(change string, ps []netip.Prefix) []diffOutput {
+	o := make([]diffOutput, len(ps))
+	for i, p := range prefixOutputs(ps) {
+		o[i] = diffOutput{Change: change, Prefix: p.Prefix, First: p.First, Last: p.Last, Addresses: p.Addresses}
+	}
+	return o
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the addresses covered by two sets of networks",
+	Long: `Diff compares the addresses covered by two sets of CIDRs instead of their lines,
+and prints the prefixes gained (+), lost (-) and unchanged ( ) from the old set to the new one,
+each as the smallest list of prefixes. Sets that cover the same addresses are reported as
+equivalent, even if they are written differently, for example after aggregation.
+Each set is a comma separated list of CIDRs, a file with one CIDR per line, or "-" for stdin.
+The output is meant to be read; use -o csv, -o json or -o ndjson to process it further.
+Structured output only lists the rows, with no "gained" or "lost" rows for equivalent sets.
+To check for equivalence in a script, use --exit-code, which exits with status 1 unless
+the sets cover the same addresses, whatever the output format.`,
+	Example: `cidr diff old.txt new.txt
+cidr diff 10.0.0.0/24,10.0.1.0/24 10.0.0.0/23
+git show HEAD~1:allowlist.txt | cidr diff - allowlist.txt --exit-code
+cidr diff old.txt new.txt -o json | jq -r '.[] | select(.change == "gained") | .prefix'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.PrintErrln("Usage: cidr diff <old set> <new set>")
+			os.Exit(1)
+		}
+
+		d := network.Diff(readSet(cmd, args[0]), readSet(cmd, args[1]))
+		if f, ok := outputFormatter(cmd); ok {
+			o := diffOutputs("gained", d.Gained)
+			o = append(o, diffOutputs("lost", d.Lost)...)
+			o = append(o, diffOutputs("unchanged", d.Unchanged)...)
+			printResult(cmd, f, o)
+		} else {
+			printDiff(cmd, d)
+		}
+
+		if exit, _ := cmd.Flags().GetBool("exit-code"); exit && !d.Equivalent() {
+			os.Exit(1)
+		}
+	},
+}
+
+// printDiff prints every prefix marked as in a unified diff, followed by a summary.
+func printDiff(cmd *cobra.Command, d network.SetDiff) {
+	for _, p := range d.Gained {
+		cmd.Printf("%s+ %s%s\n", output.Green, p, output.Reset)
+	}
+	for _, p := range d.Lost {
+		cmd.Printf("%s- %s%s\n", output.Red, p, output.Reset)
+	}
+	for _, p := range d.Unchanged {
+		cmd.Printf("  %s\n", p)
+	}
+
+	if d.Equivalent() {
+		cmd.Printf("# equivalent: both sets cover the same %s addresses\n", network.Size(d.Unchanged))
+		return
+	}
+	cmd.Printf("# %s addresses gained, %s lost, %s unchanged\n",
+		network.Size(d.Gained), network.Size(d.Lost), network.Size(d.Unchanged))
+}
@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"context"
+	"net/netip"
 	"os"
 	"strconv"
 
+	"github.com/jokarl/go-learning-projects/cidr/input"
 	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/jokarl/go-learning-projects/cidr/network/types"
 	"github.com/jokarl/go-learning-projects/cidr/output"
@@ -16,56 +18,118 @@ var vlsm bool
 func init() {
 	rootCmd.AddCommand(divideCmd)
 	divideCmd.Flags().BoolP("vlsm", "v", false, "Use Variable Length Subnet Masking (VLSM) to divide the CIDR into subnets of different sizes")
+	divideCmd.Flags().Bool("nibble", false, "Round v6 subnets to a 4-bit boundary, so each can be delegated as a reverse DNS zone")
+	divideCmd.Flags().IntP("count", "n", 0, "Subnet count, to divide every CIDR given as argument, with --file or on stdin")
+	addInputFlags(divideCmd, "CIDRs")
+	addOutputFlag(divideCmd, "")
 }
 
 var divideCmd = &cobra.Command{
 	Use:     "divide",
 	Short:   "Divide a CIDR into smaller subnets",
 	Aliases: []string{"d"},
-	Example: "cidr divide 10.0.0.0/16 4",
+	Example: `cidr divide 10.0.0.0/16 4
+cidr divide --count 4 -f networks.txt
+cidr divide 10.0.0.0/16 4 -o csv
+cidr divide 2001:db8::/48 12 --nibble`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	PreRun: func(cmd *cobra.Command, args []string) {
-		if len(args) != 2 {
-			cmd.PrintErrln("Usage: cidr divide <CIDR> [<subnet count>]")
-			os.Exit(1)
+		c, _ := cmd.Flags().GetInt("count")
+		if !cmd.Flags().Changed("count") {
+			// Classic form: cidr divide <CIDR> <count>
+			if len(args) != 2 {
+				cmd.PrintErrln("Usage: cidr divide <CIDR> <subnet count> | cidr divide --count <subnet count> [<CIDR> ...]")
+				os.Exit(1)
+			}
+
+			var err error
+			c, err = strconv.Atoi(args[1])
+			if err != nil {
+				cmd.PrintErrf("Invalid subnet count: %s\n", err)
+				os.Exit(1)
+			}
+			args = args[:1]
 		}
 
-		// Validate CIDR format
-		n, err := network.New(args[0])
-		if err != nil {
-			cmd.PrintErrf("Invalid CIDR: %s\n", err)
+		if c <= 0 {
+			cmd.PrintErrln("count must be > 0")
 			os.Exit(1)
 		}
-
-		c, err := strconv.Atoi(args[1])
-		if err != nil {
-			cmd.PrintErrf("Invalid subnet count: %s\n", err)
+		if c > network.MaxSubnets {
+			cmd.PrintErrf("count must be at most %d\n", network.MaxSubnets)
 			os.Exit(1)
 		}
 
-		if c <= 0 {
-			cmd.PrintErrf("count must be > 0")
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr divide <CIDR> <subnet count> | cidr divide --count <subnet count> [<CIDR> ...]")
+			os.Exit(1)
 		}
 
+		// Validate CIDR format
+		networks, parsed, ok := parseLines(cmd, lines, newNetwork)
+
 		vlsm, _ = cmd.Flags().GetBool("vlsm")
-		if c&(c-1) != 0 && !vlsm {
-			cmd.Println(output.Yellow, "Warning: count is not a power of two; extra subnets will be unused. Use --vlsm.", output.Reset)
+		nibble, _ := cmd.Flags().GetBool("nibble")
+		if vlsm && nibble {
+			cmd.PrintErrln("--nibble cannot be combined with --vlsm")
+			os.Exit(1)
+		}
+		if c&(c-1) != 0 && !vlsm && !nibble {
+			cmd.PrintErrln(output.Yellow, "Warning: count is not a power of two; extra subnets will be unused. Use --vlsm.", output.Reset)
 		}
 
 		cmd.SetContext(context.WithValue(cmd.Context(), "validatedCount", c))
-		cmd.SetContext(context.WithValue(cmd.Context(), "validatedNetwork", n))
+		cmd.SetContext(context.WithValue(cmd.Context(), "validatedNetworks", networks))
+		cmd.SetContext(context.WithValue(cmd.Context(), "validatedLines", parsed))
+		cmd.SetContext(context.WithValue(cmd.Context(), "validatedAll", ok))
 		cmd.SetContext(context.WithValue(cmd.Context(), "vlsm", vlsm))
+		cmd.SetContext(context.WithValue(cmd.Context(), "nibble", nibble))
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		n := cmd.Context().Value("validatedNetwork").(types.Network)
+		networks := cmd.Context().Value("validatedNetworks").([]types.Network)
 		count := cmd.Context().Value("validatedCount").(int)
-		subnets, err := n.Divide(count, cmd.Context().Value("vlsm").(bool))
-		if err != nil {
-			cmd.PrintErrf("Could not divide: %s\n", err)
-			os.Exit(1)
-		}
+		lines := cmd.Context().Value("validatedLines").([]input.Line)
+		ok := cmd.Context().Value("validatedAll").(bool)
+		nibble := cmd.Context().Value("nibble").(bool)
+		f, structured := outputFormatter(cmd)
+
+		o := []subnetOutput{}
+		for i, n := range networks {
+			var subnets []netip.Prefix
+			var err error
+			if nibble {
+				subnets, err = network.DivideNibble(n.Prefix(), count)
+			} else {
+				subnets, err = n.Divide(count, cmd.Context().Value("vlsm").(bool))
+			}
+			if err != nil {
+				cmd.PrintErrf("Could not divide: %s: %s\n", lines[i], err)
+				ok = false
+				continue
+			}
 
-		for _, subnet := range subnets {
-			cmd.Println(subnet.String())
+			if structured {
+				for _, subnet := range subnets {
+					o = append(o, newSubnetOutput(n.Prefix().String(), subnet))
+				}
+				continue
+			}
+
+			// Label each group when dividing several networks; comment
+			// lines are skipped when the output is read back as input.
+			if len(networks) > 1 {
+				cmd.Printf("# %s\n", lines[i].Text)
+			}
+			for _, subnet := range subnets {
+				cmd.Println(subnet.String())
+			}
+		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if !ok {
+			os.Exit(1)
 		}
 	},
 }
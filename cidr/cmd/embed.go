@@ -3,37 +3,67 @@ package cmd
 import (
 	"os"
 
-	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(embedCmd)
+	addInputFlags(embedCmd, "v4 addresses")
+	addOutputFlag(embedCmd, "")
+}
+
+// embedOutput pairs a v4 address with the v6 address it is embedded in.
+type embedOutput struct {
+	IPv4 string `json:"ipv4" tabs:"IPv4"`
+	IPv6 string `json:"ipv6" tabs:"IPv6"`
 }
 
 var embedCmd = &cobra.Command{
 	Use:     "embed",
 	Short:   "Embed a v4 address in a v6 address",
 	Aliases: []string{"e"},
-	Example: "cidr embed 2001:db8::/32 192.0.2.33",
+	Example: `cidr embed 2001:db8::/32 192.0.2.33
+cidr embed 64:ff9b::/96 -f addresses.txt`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 2 {
+		if len(args) < 1 {
 			cmd.PrintErrln("Usage: cidr embed <v6 CIDR> <v4 address1> <v4 address2> ...")
 			os.Exit(1)
 		}
 
-		n, err := network.New(args[0])
+		n, err := newNetwork(args[0])
 		if err != nil {
 			cmd.PrintErrf("Error: %s\n", err)
 			os.Exit(1)
 		}
-		v4 := args[1:]
-		for _, ip := range v4 {
-			addr, err := n.Embed(ip)
+
+		lines := readLines(cmd, args[1:])
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr embed <v6 CIDR> <v4 address1> <v4 address2> ...")
+			os.Exit(1)
+		}
+
+		f, structured := outputFormatter(cmd)
+		o := []embedOutput{}
+		failed := false
+		for _, l := range lines {
+			addr, err := n.Embed(l.Text)
 			if err != nil {
-				cmd.PrintErrf("Error embedding %s in %s: %s\n", ip, args[0], err)
+				cmd.PrintErrf("Error: %s: embedding %s in %s: %s\n", l, l.Text, args[0], err)
+				failed = true
+				continue
+			}
+			if structured {
+				o = append(o, embedOutput{IPv4: l.Text, IPv6: addr.String()})
+				continue
 			}
 			cmd.Println(addr.String())
 		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if failed {
+			os.Exit(1)
+		}
 	},
 }
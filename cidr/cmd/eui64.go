@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"net"
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(eui64Cmd)
+	addInputFlags(eui64Cmd, "MAC addresses")
+	addOutputFlag(eui64Cmd, "")
+}
+
+// eui64Output pairs a MAC address with the SLAAC address derived from it.
+type eui64Output struct {
+	MAC     string `json:"mac" tabs:"MAC"`
+	Address string `json:"address" tabs:"Address"`
+}
+
+var eui64Cmd = &cobra.Command{
+	Use:   "eui64",
+	Short: "Build the SLAAC address of a MAC address with modified EUI-64",
+	Long: `EUI64 builds the address SLAAC assigns to an interface from its MAC address (RFC 4291 appendix A):
+ff:fe is inserted in the middle of the MAC and the universal/local bit is flipped.
+The prefix must be a /64 or shorter; its first /64 is used. Use mac for the reverse.`,
+	Example: `cidr eui64 2001:db8::/64 00:11:22:33:44:55
+cidr eui64 fe80::/64 -f macs.txt -o csv`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			cmd.PrintErrln("Usage: cidr eui64 <v6 CIDR> <MAC1> <MAC2> ...")
+			os.Exit(1)
+		}
+
+		p, err := parsePrefix(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		lines := readLines(cmd, args[1:])
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr eui64 <v6 CIDR> <MAC1> <MAC2> ...")
+			os.Exit(1)
+		}
+
+		f, structured := outputFormatter(cmd)
+		o := []eui64Output{}
+		failed := false
+		for _, l := range lines {
+			mac, err := net.ParseMAC(l.Text)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+			addr, err := network.EUI64(p, mac)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+			if structured {
+				o = append(o, eui64Output{MAC: mac.String(), Address: addr.String()})
+				continue
+			}
+			cmd.Println(addr.String())
+		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
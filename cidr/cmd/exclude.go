@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(excludeCmd)
+	addOutputFlag(excludeCmd, "")
+}
+
+var excludeCmd = &cobra.Command{
+	Use:   "exclude",
+	Short: "Remove CIDRs from a set of networks",
+	Long: `Exclude removes one or more sets of CIDRs from the first set and prints what is left
+as the smallest list of prefixes.
+Each set is a comma separated list of CIDRs, a file with one CIDR per line, or "-" for stdin.`,
+	Aliases: []string{"x", "minus"},
+	Example: `cidr exclude 10.0.0.0/8 10.1.0.0/16,10.2.0.0/16
+cidr exclude 10.0.0.0/8 subnets.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			cmd.PrintErrln("Usage: cidr exclude <set> <set to remove> [<set to remove> ...]")
+			os.Exit(1)
+		}
+
+		result := readSet(cmd, args[0])
+		for _, arg := range args[1:] {
+			result = network.Exclude(result, readSet(cmd, arg))
+		}
+		printPrefixes(cmd, network.Aggregate(result))
+	},
+}
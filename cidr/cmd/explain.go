@@ -5,55 +5,145 @@ import (
 	"os"
 	"strings"
 
+	"github.com/jokarl/go-learning-projects/cidr/input"
 	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/jokarl/go-learning-projects/cidr/output"
 	"github.com/spf13/cobra"
 )
 
-var format string
-
 func init() {
 	rootCmd.AddCommand(explainCmd)
 
-	explainCmd.Flags().StringVarP(
-		&format,
-		"out",
-		"o",
-		output.DefaultFormat, // default to "tab"
-		fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", ")),
-	)
+	addOutputFlag(explainCmd, output.DefaultFormat)
+	addInputFlags(explainCmd, "CIDRs")
+	explainCmd.Flags().Bool("bits", false, "Print the address, netmask and broadcast in binary, or in hex nibbles for v6")
+	explainCmd.Flags().IntP("divide", "d", 0, "With --bits, mark the bits borrowed to divide the network into this many subnets")
 }
 
 var explainCmd = &cobra.Command{
 	Use:   "explain",
 	Short: "Explain CIDR notation",
 	Long: `Explain CIDR notation by providing the base address of the network.
-It is possible to pass any number of CIDR notated networks, and mixing v4 and v6 addresses.`,
+It is possible to pass any number of CIDR notated networks, and mixing v4 and v6 addresses.
+CIDRs can also be read from files given with --file, or from stdin.
+v6 addresses of the 6to4, Teredo, ISATAP, IPv4-mapped, IPv4-compatible and NAT64 mechanisms
+are decoded as far as the prefix length covers them, so pass a /128 to decode a whole address.
+
+With --bits, the address, base address, netmask and broadcast (or last address for v6) are
+printed in binary, with network and host bits in different colours. --divide also marks the
+bits a divide into that many subnets borrows. Without colours, network bits are enclosed
+in [] and borrowed bits in {}.`,
 	Aliases: []string{"e"},
 	Example: `cidr explain 10.0.0.0/16
-cidr explain 2001:db8::/32`,
+cidr explain 2001:db8::/32
+cidr explain 2001:0:4136:e378:8000:63bf:3fff:fdd2/128
+cidr explain -f networks.txt
+cidr explain 10.1.2.3/22 --bits --divide 4`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 1 {
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
 			cmd.PrintErrln("Usage: cidr explain <CIDR> [<CIDR> ...]")
 			os.Exit(1)
 		}
 
-		f, err := output.GetFormatter(format)
-		if err != nil {
-			cmd.PrintErrf("Unknown output format: %s\n", format)
+		bits, _ := cmd.Flags().GetBool("bits")
+		divide, _ := cmd.Flags().GetInt("divide")
+		if cmd.Flags().Changed("divide") && !bits {
+			cmd.PrintErrln("--divide requires --bits")
 			os.Exit(1)
 		}
-
-		for _, arg := range args {
-			n, err := network.New(arg)
-			if err != nil {
-				cmd.PrintErrf("Error: %s\n", err)
+		if bits {
+			if cmd.Flags().Changed("out") {
+				cmd.PrintErrln("--bits cannot be combined with --out")
 				os.Exit(1)
 			}
-			if err := network.PrintNetwork(n, f); err != nil {
-				cmd.PrintErrf("Error printing network %s: %s\n", arg, err)
+			if !explainBits(cmd, lines, divide) {
 				os.Exit(1)
 			}
+			return
+		}
+
+		f, _ := outputFormatter(cmd)
+
+		networks, _, ok := parseLines(cmd, lines, newNetwork)
+		o := make([]network.Explanation, len(networks))
+		for i, n := range networks {
+			o[i] = network.Explain(n)
+		}
+		// The tab formatter prints one block per network, and a single network is
+		// printed as an object rather than a list of one.
+		if _, isTab := f.(*output.TabFormatter); isTab || len(o) == 1 {
+			for _, e := range o {
+				printResult(cmd, f, e)
+			}
+		} else if len(o) > 1 {
+			printResult(cmd, f, o)
+		}
+		if !ok {
+			os.Exit(1)
 		}
 	},
 }
+
+// explainBits prints every network in binary, marking the bits borrowed to divide
+// it into divide subnets if divide is set. It returns false if any line failed.
+func explainBits(cmd *cobra.Command, lines []input.Line, divide int) bool {
+	prefixes, parsed, ok := parseLines(cmd, lines, parsePrefix)
+	for i, p := range prefixes {
+		layout := network.BitLayout{Network: p.Bits()}
+		if divide != 0 {
+			var err error
+			if layout, err = network.DivideLayout(p, divide); err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", parsed[i], err)
+				ok = false
+				continue
+			}
+		}
+
+		if i > 0 {
+			cmd.Println()
+		}
+		cmd.Println(p)
+		for _, r := range network.BitRows(p) {
+			cmd.Printf("  %-13s %s  %s\n", r.Label, markBits(network.FormatBits(r.Addr, layout)), r.Addr)
+		}
+		cmd.Printf("  %-13s %s\n", "", bitLegend(layout.Borrowed > 0))
+	}
+	return ok
+}
+
+var bitColor = map[network.BitKind]fmt.Stringer{
+	network.NetworkBit: output.Green,
+	network.SubnetBit:  output.Yellow,
+	network.HostBit:    output.Cyan,
+}
+
+var bitMarks = map[network.BitKind][2]string{
+	network.NetworkBit: {"[", "]"},
+	network.SubnetBit:  {"{", "}"},
+}
+
+// markBits writes the runs of an address with a colour for each part of the address.
+// With colours off, network bits are enclosed in [] and borrowed bits in {} instead.
+func markBits(runs []network.BitRun) string {
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.Sep)
+		if output.ColorEnabled() {
+			b.WriteString(bitColor[r.Kind].String() + r.Text + output.Reset.String())
+		} else {
+			b.WriteString(bitMarks[r.Kind][0] + r.Text + bitMarks[r.Kind][1])
+		}
+	}
+	return b.String()
+}
+
+// bitLegend names the parts of an address the way markBits marks them.
+func bitLegend(borrowed bool) string {
+	runs := []network.BitRun{{Kind: network.NetworkBit, Text: "network"}}
+	if borrowed {
+		runs = append(runs, network.BitRun{Sep: " ", Kind: network.SubnetBit, Text: "subnet"})
+	}
+	return markBits(append(runs, network.BitRun{Sep: " ", Kind: network.HostBit, Text: "host"}))
+}
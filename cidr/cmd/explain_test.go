@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+)
+
+// With colours off, network bits are enclosed in [] and borrowed bits in {},
+// even where the boundary falls inside a hex digit.
+func TestMarkBits(t *testing.T) {
+	tests := []struct {
+		addr   string
+		layout network.BitLayout
+		want   string
+	}{
+		{"10.1.2.3", network.BitLayout{Network: 22}, "[00001010.00000001.000000]10.00000011"},
+		{"10.1.2.3", network.BitLayout{Network: 22, Borrowed: 2}, "[00001010.00000001.000000]{10}.00000011"},
+		{"10.1.2.3", network.BitLayout{Network: 24, Borrowed: 8}, "[00001010.00000001.00000010].{00000011}"},
+		{"0.0.0.0", network.BitLayout{}, "00000000.00000000.00000000.00000000"},
+		{"2001:db8::1", network.BitLayout{Network: 32, Borrowed: 16}, "[2001:0db8]:{0000}:0000:0000:0000:0000:0001"},
+		{"2001:db8:abcd::", network.BitLayout{Network: 46, Borrowed: 2}, "[2001:0db8:abc(11]{01)}:0000:0000:0000:0000:0000"},
+	}
+	defer output.SetColor(output.ColorEnabled())
+	output.SetColor(false)
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got := markBits(network.FormatBits(netip.MustParseAddr(tt.addr), tt.layout))
+			if got != tt.want {
+				t.Errorf(`markBits(FormatBits(%s, %+v)) = %q, want %q`, tt.addr, tt.layout, got, tt.want)
+			}
+		})
+	}
+}
+
+// Separators stay outside the colours, so dots are never coloured as network bits.
+func TestMarkBitsColor(t *testing.T) {
+	// arrange
+	defer output.SetColor(output.ColorEnabled())
+	output.SetColor(true)
+	want := output.Green.String() + "00001010.00000001.00000010" + output.Reset.String() + "." +
+		output.Cyan.String() + "00000011" + output.Reset.String()
+
+	// act
+	got := markBits(network.FormatBits(netip.MustParseAddr("10.1.2.3"), network.BitLayout{Network: 24}))
+
+	// assert
+	if got != want {
+		t.Errorf(`markBits = %q, want %q`, got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// wellKnownPrefix is the NAT64 well-known prefix from RFC 6052.
+const wellKnownPrefix = "64:ff9b::/96"
+
+var extractPrefix string
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+	extractCmd.Flags().StringVarP(&extractPrefix, "prefix", "p", wellKnownPrefix, "v6 CIDR the v4 addresses are embedded in")
+	addInputFlags(extractCmd, "v6 addresses")
+	addOutputFlag(extractCmd, "")
+}
+
+var extractCmd = &cobra.Command{
+	Use:   "extract",
+	Short: "Extract a v4 address from a v6 address",
+	Long: `Extract recovers the v4 address embedded in a v6 address, e.g. one synthesized by NAT64/DNS64.
+It is the inverse of embed and supports the prefix lengths 32, 40, 48, 56, 64 and 96 from RFC 6052.
+Without --prefix, the well-known prefix 64:ff9b::/96 is used.`,
+	Aliases: []string{"unembed"},
+	Example: `cidr extract 64:ff9b::c000:221
+cidr extract --prefix 2001:db8::/32 2001:db8:c000:221::`,
+	Run: func(cmd *cobra.Command, args []string) {
+		n, err := newNetwork(extractPrefix)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr extract [--prefix <v6 CIDR>] <v6 address1> <v6 address2> ...")
+			os.Exit(1)
+		}
+
+		f, structured := outputFormatter(cmd)
+		o := []embedOutput{}
+		failed := false
+		for _, l := range lines {
+			addr, err := n.Extract(l.Text)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: extracting from %s: %s\n", l, l.Text, err)
+				failed = true
+				continue
+			}
+			if structured {
+				o = append(o, embedOutput{IPv4: addr.String(), IPv6: l.Text})
+				continue
+			}
+			cmd.Println(addr.String())
+		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
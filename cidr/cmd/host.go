@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(hostCmd)
+	addOutputFlag(hostCmd, "")
+}
+
+// hostAddressOutput is the address of a host number within a network.
+type hostAddressOutput struct {
+	Network string `json:"network" tabs:"Network"`
+	Hostnum string `json:"hostnum" tabs:"Hostnum"`
+	Address string `json:"address" tabs:"Address"`
+}
+
+var hostCmd = &cobra.Command{
+	Use:   "host",
+	Short: "Calculate a host address like Terraform's cidrhost",
+	Long: `Host calculates the address of a host within a given network, with the same semantics and errors
+as Terraform's cidrhost(prefix, hostnum) function. hostnum counts from the network address at 0;
+negative numbers count back from the last address at -1.`,
+	Aliases: []string{"cidrhost"},
+	Example: `cidr host 10.12.112.0/20 16
+cidr host 10.12.112.0/20 -- -1`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			cmd.PrintErrln("Usage: cidr host <CIDR> <hostnum>")
+			os.Exit(1)
+		}
+		hostnum, err := parseTerraformNumber("hostnum", args[1])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		a, err := network.CIDRHost(args[0], hostnum)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if f, ok := outputFormatter(cmd); ok {
+			printResult(cmd, f, []hostAddressOutput{{Network: args[0], Hostnum: hostnum.String(), Address: a.String()}})
+			return
+		}
+		cmd.Println(a.String())
+	},
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostsUsableOnly bool
+	hostsOffset     uint64
+	hostsLimit      uint64
+	hostsStep       uint64
+)
+
+func init() {
+	rootCmd.AddCommand(hostsCmd)
+	hostsCmd.Flags().BoolVarP(&hostsUsableOnly, "usable-only", "u", false, "Skip the network and broadcast addresses (first and last address for v6)")
+	hostsCmd.Flags().Uint64Var(&hostsOffset, "offset", 0, "Number of addresses to skip before the first one printed")
+	hostsCmd.Flags().Uint64VarP(&hostsLimit, "limit", "n", 0, "Maximum number of addresses to print per network (0 means no limit)")
+	hostsCmd.Flags().Uint64Var(&hostsStep, "step", 1, "Print every n-th address")
+	addInputFlags(hostsCmd, "CIDRs")
+	addOutputFlag(hostsCmd, "")
+}
+
+// maxHostRows is the most addresses collected for formatters that print all rows at once.
+// ndjson streams and has no limit.
+const maxHostRows = 1 << 20
+
+// hostOutput is an address of a network listed by hosts.
+type hostOutput struct {
+	Network string `json:"network" tabs:"Network"`
+	Address string `json:"address" tabs:"Address"`
+}
+
+var hostsCmd = &cobra.Command{
+	Use:   "hosts",
+	Short: "List the addresses in a CIDR network",
+	Long: `Hosts prints the addresses of one or more networks, one per line.
+Addresses are generated while printing, so even large v6 networks can be listed;
+use --limit to stop after a number of addresses. With -o, every address is a row;
+all formats except ndjson hold the rows in memory, so they print at most 1048576 addresses.`,
+	Aliases: []string{"h", "enumerate"},
+	Example: `cidr hosts --usable-only 10.0.0.0/22
+cidr hosts --limit 50 2001:db8::/64
+cidr hosts --offset 10 --step 4 --limit 8 192.168.0.0/24`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr hosts <CIDR> [<CIDR> ...]")
+			os.Exit(1)
+		}
+		networks, _, ok := parseLines(cmd, lines, newNetwork)
+
+		if f, structured := outputFormatter(cmd); structured {
+			if !printHostRows(cmd, newRowWriter[hostOutput](cmd, f), networks) {
+				os.Exit(1)
+			}
+			if !ok {
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := bufio.NewWriter(cmd.OutOrStdout())
+		for _, n := range networks {
+			var printed uint64
+			for a := range n.Hosts(hostsUsableOnly, hostsOffset, hostsStep) {
+				if hostsLimit > 0 && printed == hostsLimit {
+					break
+				}
+				if _, err := fmt.Fprintln(w, a); err != nil {
+					// Most likely a closed pipe, e.g. when piped into head.
+					os.Exit(1)
+				}
+				printed++
+			}
+		}
+		_ = w.Flush()
+		if !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+// printHostRows prints the addresses of networks as rows. It returns false
+// if the formatter holds every row and there are more than maxHostRows.
+func printHostRows(cmd *cobra.Command, w *rowWriter[hostOutput], networks []types.Network) bool {
+	var total uint64
+	for _, n := range networks {
+		var printed uint64
+		for a := range n.Hosts(hostsUsableOnly, hostsOffset, hostsStep) {
+			if hostsLimit > 0 && printed == hostsLimit {
+				break
+			}
+			if total++; !w.streams() && total > maxHostRows {
+				cmd.PrintErrf("Error: more than %d addresses; use --limit or -o ndjson\n", maxHostRows)
+				return false
+			}
+			w.add(hostOutput{Network: n.Prefix().String(), Address: a.String()})
+			printed++
+		}
+	}
+	w.flush()
+	return true
+}
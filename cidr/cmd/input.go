@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/input"
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/network/types"
+	"github.com/spf13/cobra"
+)
+
+// strict is set with --strict.
+var strict bool
+
+// inputFiles holds the files given with --file.
+var inputFiles []string
+
+// addInputFlags registers --file on a command that reads its entries
+// from the arguments, from files and from stdin.
+func addInputFlags(c *cobra.Command, what string) {
+	c.Flags().StringSliceVarP(&inputFiles, "file", "f", nil, fmt.Sprintf("Read %s from file, one per line (\"-\" for stdin)", what))
+}
+
+// readLines reads the entries given as args, with --file and on stdin.
+// It exits if a source cannot be read.
+func readLines(cmd *cobra.Command, args []string) []input.Line {
+	return readLinesFrom(cmd, args, inputFiles)
+}
+
+func readLinesFrom(cmd *cobra.Command, args, files []string) []input.Line {
+	lines, err := input.Read(args, files, cmd.InOrStdin())
+	if err != nil {
+		cmd.PrintErrf("Error: %s\n", err)
+		os.Exit(1)
+	}
+	return lines
+}
+
+// parseLines parses every line with parse. Lines that fail are reported with their
+// position instead of stopping at the first bad entry, and ok is false if any failed.
+// The returned lines are the ones values were parsed from.
+func parseLines[T any](cmd *cobra.Command, lines []input.Line, parse func(string) (T, error)) (values []T, parsed []input.Line, ok bool) {
+	ok = true
+	for _, l := range lines {
+		v, err := parse(l.Text)
+		if err != nil {
+			cmd.PrintErrf("Error: %s: %s\n", l, err)
+			ok = false
+			continue
+		}
+		values = append(values, v)
+		parsed = append(parsed, l)
+	}
+	return values, parsed, ok
+}
+
+// parsePrefix parses a prefix in any notation network.ParsePrefix accepts.
+// With --strict, prefixes with host bits set are rejected instead of masked later.
+func parsePrefix(s string) (netip.Prefix, error) {
+	p, err := network.ParsePrefix(s)
+	if err == nil && strict {
+		err = network.CheckHostBits(p)
+	}
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return p, nil
+}
+
+// parsePrefixes is like parsePrefix, but also accepts ranges that need several prefixes.
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	ps, err := network.ParsePrefixes(s)
+	if err != nil {
+		return nil, err
+	}
+	if strict {
+		for _, p := range ps {
+			if err := network.CheckHostBits(p); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ps, nil
+}
+
+// newNetwork is network.New with --strict applied.
+func newNetwork(s string) (types.Network, error) {
+	p, err := parsePrefix(s)
+	if err != nil {
+		return nil, err
+	}
+	return network.New(p.String())
+}
+
+// parseAddr parses a single address.
+func parseAddr(s string) (netip.Addr, error) {
+	return netip.ParseAddr(s)
+}
+
+// addrParser returns a parser for single addresses of the same family as n.
+func addrParser(n types.Network) func(string) (netip.Addr, error) {
+	return func(s string) (netip.Addr, error) {
+		a, err := parseAddr(s)
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		if a.Is4() != n.Prefix().Addr().Is4() {
+			return netip.Addr{}, fmt.Errorf("%s is not in the address family of %s", a, n.Prefix())
+		}
+		return a, nil
+	}
+}
+
+// readPrefixes reads CIDRs from args, files and stdin. Ranges are read as the prefixes covering them.
+// Every invalid entry is reported before exiting, as a partial set would give wrong results.
+func readPrefixes(cmd *cobra.Command, args, files []string) []netip.Prefix {
+	sets, _, ok := parseLines(cmd, readLinesFrom(cmd, args, files), parsePrefixes)
+	if !ok {
+		os.Exit(1)
+	}
+	return slices.Concat(sets...)
+}
+
+// readSet reads a set of CIDRs from a single argument.
+// The argument is either a comma separated list of CIDRs,
+// a path to a file with one CIDR per line, or "-" for stdin.
+func readSet(cmd *cobra.Command, arg string) []netip.Prefix {
+	if arg == "-" {
+		return readPrefixes(cmd, []string{arg}, nil)
+	}
+
+	parts := strings.Split(arg, ",")
+	prefixes := make([]netip.Prefix, 0, len(parts))
+	var parseErr error
+	for _, part := range parts {
+		ps, err := parsePrefixes(part)
+		if err != nil {
+			parseErr = err
+			break
+		}
+		prefixes = append(prefixes, ps...)
+	}
+	if parseErr == nil {
+		return prefixes
+	}
+
+	// Not a list, so arg is a file; if it is not one either, the parse error says what is wrong.
+	if _, err := os.Stat(arg); err != nil {
+		cmd.PrintErrf("Error: %q is neither a CIDR list nor a readable file: %s\n", arg, parseErr)
+		os.Exit(1)
+	}
+	return readPrefixes(cmd, nil, []string{arg})
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(intersectCmd)
+	addOutputFlag(intersectCmd, "")
+}
+
+var intersectCmd = &cobra.Command{
+	Use:   "intersect",
+	Short: "Print the addresses shared by all sets of networks",
+	Long: `Intersect prints the addresses covered by every given set of CIDRs
+as the smallest list of prefixes.
+Each set is a comma separated list of CIDRs, a file with one CIDR per line, or "-" for stdin.`,
+	Aliases: []string{"and"},
+	Example: `cidr intersect 10.0.0.0/8 10.1.0.0/16,192.168.0.0/16
+cidr intersect a.txt b.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			cmd.PrintErrln("Usage: cidr intersect <set> <set> [<set> ...]")
+			os.Exit(1)
+		}
+
+		result := readSet(cmd, args[0])
+		for _, arg := range args[1:] {
+			result = network.Intersect(result, readSet(cmd, arg))
+		}
+		printPrefixes(cmd, network.Aggregate(result))
+	},
+}
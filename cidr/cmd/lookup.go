@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/input"
+	"github.com/jokarl/go-learning-projects/cidr/trie"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lookupTable   string
+	lookupAll     bool
+	lookupDefault string
+)
+
+func init() {
+	rootCmd.AddCommand(lookupCmd)
+	lookupCmd.Flags().StringVarP(&lookupTable, "table", "t", "", "CSV file with one \"name,CIDR\" row per network")
+	lookupCmd.Flags().BoolVarP(&lookupAll, "all", "a", false, "Print every matching network from the least to the most specific, separated by ';'")
+	lookupCmd.Flags().StringVar(&lookupDefault, "default", "", "Label printed for addresses that match no network")
+	addInputFlags(lookupCmd, "addresses")
+	addOutputFlag(lookupCmd, "")
+	if err := lookupCmd.MarkFlagRequired("table"); err != nil {
+		os.Exit(1)
+	}
+}
+
+// lookupOutput is an address with the network it matched. With --all, names and
+// prefixes list every match from the least to the most specific, separated by ';'.
+type lookupOutput struct {
+	Address string `json:"address" tabs:"Address"`
+	Name    string `json:"name" tabs:"Name"`
+	Prefix  string `json:"prefix" tabs:"Prefix"`
+}
+
+var lookupCmd = &cobra.Command{
+	Use:   "lookup",
+	Short: "Classify addresses against a table of labeled networks",
+	Long: `Lookup finds the most specific network in a table that contains each address (longest prefix match)
+and prints "address,label,network" as CSV. The table is a CSV file with "name,CIDR" rows; a header row,
+blank lines and lines starting with '#' are skipped. Addresses are read from the arguments, from files
+given with --file, or from stdin, and are processed as they are read so large logs can be streamed through.
+With -o, every address is a row with its address, name and prefix; only ndjson streams.`,
+	Aliases: []string{"lpm", "classify"},
+	Example: `cidr lookup --table nets.csv 10.1.2.3
+cut -d' ' -f1 access.log | cidr lookup --table nets.csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		t, err := loadTable(lookupTable)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		out := bufio.NewWriter(cmd.OutOrStdout())
+		w := csv.NewWriter(out)
+		f, structured := outputFormatter(cmd)
+		rows := newRowWriter[lookupOutput](cmd, f)
+		failed := false
+		for l, err := range input.Scan(args, inputFiles, cmd.InOrStdin()) {
+			if err != nil {
+				w.Flush()
+				cmd.PrintErrf("Error: %s\n", err)
+				os.Exit(1)
+			}
+			a, err := netip.ParseAddr(l.Text)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+
+			label, match := lookupDefault, ""
+			if lookupAll {
+				var labels, matches []string
+				for p, name := range t.Covering(netip.PrefixFrom(a, a.BitLen())) {
+					labels = append(labels, name)
+					matches = append(matches, p.String())
+				}
+				if len(labels) > 0 {
+					label, match = strings.Join(labels, ";"), strings.Join(matches, ";")
+				}
+			} else if p, name, ok := t.Lookup(a); ok {
+				label, match = name, p.String()
+			}
+
+			if structured {
+				rows.add(lookupOutput{Address: a.String(), Name: label, Prefix: match})
+				continue
+			}
+			if err := w.Write([]string{a.String(), label, match}); err != nil {
+				os.Exit(1)
+			}
+		}
+		if structured {
+			rows.flush()
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			os.Exit(1)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// loadTable reads a "name,CIDR" CSV file into a trie.
+func loadTable(path string) (*trie.Trie[string], error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	t := &trie.Trie[string]{}
+	for first := true; ; first = false {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		// Comments and blank lines are skipped, so the line is taken from the reader.
+		line, _ := r.FieldPos(0)
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"name,CIDR\"", path, line)
+		}
+
+		p, err := parsePrefix(rec[1])
+		if err != nil {
+			if first {
+				continue // header
+			}
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		t.Insert(p, strings.TrimSpace(rec[0]))
+	}
+	return t, nil
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Comments and blank lines are skipped but still counted,
+// so the error points at the line of the file.
+func TestLoadTable(t *testing.T) {
+	// arrange
+	path := filepath.Join(t.TempDir(), "table.csv")
+	table := "name,cidr\n# offices\n\nhq,10.0.0.0/16\n\n# branches\nbranch,10.1.0.0/33\n"
+	if err := os.WriteFile(path, []byte(table), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	_, err := loadTable(path)
+
+	// assert
+	if want := path + ":7: "; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf(`loadTable error = %v, want it to start with %q`, err, want)
+	}
+}
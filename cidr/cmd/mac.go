@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(macCmd)
+	addInputFlags(macCmd, "v6 addresses")
+	addOutputFlag(macCmd, "")
+}
+
+var macCmd = &cobra.Command{
+	Use:   "mac",
+	Short: "Recover the MAC address from an EUI-64 based v6 address",
+	Long: `MAC recovers the MAC address a SLAAC address was built from with modified EUI-64.
+It is the inverse of eui64 and fails for addresses whose interface identifier does not
+contain ff:fe in the middle, such as privacy or RFC 7217 stable addresses.`,
+	Aliases: []string{"uneui64"},
+	Example: `cidr mac 2001:db8::211:22ff:fe33:4455
+cidr mac -f neighbors.txt -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr mac <v6 address1> <v6 address2> ...")
+			os.Exit(1)
+		}
+
+		f, structured := outputFormatter(cmd)
+		o := []eui64Output{}
+		failed := false
+		for _, l := range lines {
+			addr, err := netip.ParseAddr(strings.TrimSpace(l.Text))
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+			mac, err := network.MAC(addr)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+			if structured {
+				o = append(o, eui64Output{MAC: mac.String(), Address: addr.String()})
+				continue
+			}
+			cmd.Println(mac.String())
+		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/addrmap"
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mapFormat  string
+	mapWidth   int
+	mapVLSM    bool
+	mapReserve []string
+)
+
+func init() {
+	rootCmd.AddCommand(mapCmd)
+	mapCmd.Flags().StringVar(&mapFormat, "format", "unicode", "Diagram format (unicode, ascii, svg, html)")
+	mapCmd.Flags().IntVarP(&mapWidth, "width", "w", 64, "Width of the bar in characters, for unicode and ascii")
+	mapCmd.Flags().BoolVar(&mapVLSM, "vlsm", false, "Read VLSM requests instead of allocated CIDRs, and map the resulting plan")
+	mapCmd.Flags().StringSliceVarP(&mapReserve, "reserve", "r", nil, "With --vlsm, CIDRs already in use, which are never allocated")
+	addInputFlags(mapCmd, "allocated CIDRs or VLSM requests")
+}
+
+var mapCmd = &cobra.Command{
+	Use:   "map",
+	Short: "Draw how the address space of a CIDR is used",
+	Long: `Map draws a proportional block diagram of a CIDR, showing its allocated subnets,
+its free space and how fragmented that free space is.
+
+Allocated subnets are given as CIDRs, optionally named as name=CIDR, so the output of
+divide can be piped in. With --vlsm, VLSM requests are given instead, as for cidr vlsm,
+and the plan is mapped with its reserved and leftover subnets.
+
+The diagram is drawn in the terminal with Unicode or ASCII blocks, or written as a
+standalone SVG image or HTML page with --format svg or --format html.
+Map takes no -o, as a diagram is not a list of rows.`,
+	Example: `cidr map 10.0.0.0/22 web=10.0.0.0/24 db=10.0.2.0/25
+cidr divide 10.0.0.0/22 4 | head -2 | cidr map 10.0.0.0/22
+cidr map 10.0.0.0/22 --vlsm web=120 db=30 --reserve 10.0.3.0/24
+cidr map 2001:db8::/48 --vlsm lan=12x/64 wan=/56 --format svg > map.svg`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			cmd.PrintErrln("Usage: cidr map <CIDR> [[<name>=]<CIDR> ...] | cidr map <CIDR> --vlsm <request> ...")
+			os.Exit(1)
+		}
+		if mapReserve != nil && !mapVLSM {
+			cmd.PrintErrln("--reserve requires --vlsm")
+			os.Exit(1)
+		}
+
+		p, err := parsePrefix(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		p = p.Masked()
+
+		var m addrmap.Map
+		if mapVLSM {
+			m, err = mapPlan(cmd, p, args[1:])
+		} else {
+			m, err = mapBlocks(cmd, p, args[1:])
+		}
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		switch mapFormat {
+		case "unicode", "ascii":
+			if mapWidth < 1 {
+				cmd.PrintErrln("width must be > 0")
+				os.Exit(1)
+			}
+			cmd.Print(m.Text(mapWidth, mapFormat == "ascii"))
+		case "svg":
+			cmd.Print(m.SVG())
+		case "html":
+			cmd.Print(m.HTML())
+		default:
+			cmd.PrintErrf("Error: unknown diagram format %q, want unicode, ascii, svg or html\n", mapFormat)
+			os.Exit(1)
+		}
+	},
+}
+
+// mapBlocks maps p from the allocated CIDRs given as args, with --file or on stdin.
+// Everything else in p is free.
+func mapBlocks(cmd *cobra.Command, p netip.Prefix, args []string) (addrmap.Map, error) {
+	blocks, _, ok := parseLines(cmd, readLines(cmd, args), parseMapBlock)
+	if !ok {
+		os.Exit(1)
+	}
+	used := make([]netip.Prefix, len(blocks))
+	for i, b := range blocks {
+		used[i] = b.Prefix
+	}
+	return addrmap.New(p, blocks, network.Exclude([]netip.Prefix{p}, used))
+}
+
+// mapPlan maps the VLSM plan for the requests given as args, with --file or on stdin.
+func mapPlan(cmd *cobra.Command, p netip.Prefix, args []string) (addrmap.Map, error) {
+	lines := readLines(cmd, args)
+	if len(lines) < 1 {
+		return addrmap.Map{}, fmt.Errorf("no VLSM requests given")
+	}
+	reqs, _, ok := parseLines(cmd, lines, parseVLSMRequest)
+	if !ok {
+		os.Exit(1)
+	}
+	var reserved []netip.Prefix
+	for _, r := range mapReserve {
+		reserved = append(reserved, readSet(cmd, r)...)
+	}
+	plan, err := planVLSM(p, reqs, network.VLSMOptions{Reserved: reserved})
+	if err != nil {
+		return addrmap.Map{}, err
+	}
+
+	var blocks []addrmap.Block
+	for _, a := range plan.hosts {
+		blocks = append(blocks, addrmap.Block{Name: a.Name, Prefix: a.Prefix})
+	}
+	for _, a := range plan.blocks {
+		blocks = append(blocks, addrmap.Block{Name: a.Name, Prefix: a.Prefix})
+	}
+	return addrmap.New(p, blocks, plan.leftover)
+}
+
+// parseMapBlock parses an allocated CIDR, optionally written as name=CIDR.
+func parseMapBlock(s string) (addrmap.Block, error) {
+	name, cidr, named := strings.Cut(s, "=")
+	if !named {
+		cidr, name = name, ""
+	}
+	p, err := parsePrefix(strings.TrimSpace(cidr))
+	if err != nil {
+		return addrmap.Block{}, err
+	}
+	return addrmap.Block{Name: strings.TrimSpace(name), Prefix: p, Status: addrmap.Allocated}, nil
+}
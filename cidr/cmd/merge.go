@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(mergeCmd)
+	addInputFlags(mergeCmd, "CIDRs")
+	addOutputFlag(mergeCmd, "")
+}
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "Merge a list of CIDRs into the smallest equivalent set",
+	Long: `Merge collapses a list of CIDRs into the smallest set of prefixes covering the same addresses.
+Duplicates and prefixes covered by larger ones are removed, and adjacent blocks are merged.
+CIDRs are read from the arguments, from files given with --file, or from stdin when neither is given.
+Blank lines and everything after a '#' are ignored. v4 and v6 networks may be mixed.`,
+	Aliases: []string{"m", "aggregate"},
+	Example: `cidr merge 10.0.0.0/25 10.0.0.128/25
+cidr merge -f allowlist.txt
+cat allowlist.txt | cidr merge`,
+	Run: func(cmd *cobra.Command, args []string) {
+		prefixes := readPrefixes(cmd, args, inputFiles)
+		printPrefixes(cmd, network.Aggregate(prefixes))
+	},
+}
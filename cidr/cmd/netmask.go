@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(netmaskCmd)
+	addOutputFlag(netmaskCmd, "")
+}
+
+// netmaskOutput is the dotted-decimal netmask of a v4 network.
+type netmaskOutput struct {
+	Network string `json:"network" tabs:"Network"`
+	Netmask string `json:"netmask" tabs:"Netmask"`
+}
+
+var netmaskCmd = &cobra.Command{
+	Use:   "netmask",
+	Short: "Print the netmask of a v4 network like Terraform's cidrnetmask",
+	Long: `Netmask prints the netmask of a v4 network in dotted-decimal notation, with the same semantics
+and errors as Terraform's cidrnetmask(prefix) function. v6 networks have no netmask notation.`,
+	Aliases:     []string{"cidrnetmask"},
+	Example:     "cidr netmask 172.16.0.0/12",
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr netmask <CIDR>")
+			os.Exit(1)
+		}
+
+		m, err := network.CIDRNetmask(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if f, ok := outputFormatter(cmd); ok {
+			printResult(cmd, f, []netmaskOutput{{Network: args[0], Netmask: m.String()}})
+			return
+		}
+		cmd.Println(m.String())
+	},
+}
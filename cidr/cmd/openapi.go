@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"encoding"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// openAPIDocument describes the endpoints as an OpenAPI 3.0 document.
+// Schemas are generated from the request and result types, so they always match what is served.
+func openAPIDocument(endpoints []apiEndpoint) map[string]any {
+	schemas := map[string]any{}
+	errorResponse := func(description string) map[string]any {
+		return map[string]any{
+			"description": description,
+			"content":     jsonContent(schemaOf(reflect.TypeFor[apiError](), schemas)),
+		}
+	}
+
+	paths := map[string]any{}
+	for _, e := range endpoints {
+		var result map[string]any
+		if len(e.responses) == 1 {
+			result = schemaOf(e.responses[0], schemas)
+		} else {
+			var oneOf []any
+			for _, t := range e.responses {
+				oneOf = append(oneOf, schemaOf(t, schemas))
+			}
+			result = map[string]any{"oneOf": oneOf}
+		}
+		paths[e.path] = map[string]any{
+			"post": map[string]any{
+				"operationId": strings.TrimPrefix(e.path, "/v1/"),
+				"summary":     e.summary,
+				"description": e.description,
+				"requestBody": map[string]any{
+					"required": true,
+					"content":  jsonContent(schemaOf(e.request, schemas)),
+				},
+				"responses": map[string]any{
+					"200": map[string]any{"description": "Result", "content": jsonContent(result)},
+					"400": errorResponse("Invalid request; details lists the entries that could not be parsed"),
+					"413": errorResponse("Request body larger than 1 MiB"),
+					"422": errorResponse("Valid request that cannot be carried out, e.g. a plan that does not fit"),
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "cidr",
+			"description": "JSON API of the cidr calculator. Results match the -o json output of the commands.",
+			"version":     "1",
+		},
+		"paths":      paths,
+		"components": map[string]any{"schemas": schemas},
+	}
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+var (
+	textMarshaler = reflect.TypeFor[encoding.TextMarshaler]()
+	bigInt        = reflect.TypeFor[big.Int]()
+)
+
+// schemaOf returns the schema of t, adding structs to schemas and referring to them by name.
+// Field names and optional fields follow the json tags, as encoding/json does.
+func schemaOf(t reflect.Type, schemas map[string]any) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == bigInt:
+		return map[string]any{"type": "integer", "description": "Arbitrary precision integer"}
+	case t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": schemaOf(t.Elem(), schemas)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": schemaOf(t.Elem(), schemas)}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := schemas[name]; !ok {
+			schemas[name] = nil // placeholder, in case the type refers to itself
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := range t.NumField() {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				addFields(f.Type) // embedded fields are promoted
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaOf(f.Type, schemas)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+
+	s := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// schemaName names the schema of t after the type, e.g. "Count" for countOutput and "Error" for apiError.
+func schemaName(t reflect.Type) string {
+	name := strings.TrimPrefix(strings.TrimSuffix(t.Name(), "Output"), "api")
+	if name == "" {
+		return "Object"
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlag registers --out on a command with structured results.
+// Commands that print plain text by default pass an empty default
+// and only use a formatter when a format is chosen.
+func addOutputFlag(c *cobra.Command, def string) {
+	usage := fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", "))
+	if def == "" {
+		usage += "; plain text if not set"
+	}
+	c.PersistentFlags().StringP("out", "o", def, usage)
+}
+
+// outputFormatter returns the formatter chosen with --out.
+// It returns false if the command should print plain text, and exits on unknown formats.
+func outputFormatter(cmd *cobra.Command) (output.Formatter, bool) {
+	name, _ := cmd.Flags().GetString("out")
+	if name == "" {
+		return nil, false
+	}
+	f, err := output.GetFormatter(name)
+	if err != nil {
+		cmd.PrintErrf("Unknown output format: %s\n", name)
+		os.Exit(1)
+	}
+	return f, true
+}
+
+// printResult writes data to stdout with f, exiting if it cannot be formatted.
+func printResult(cmd *cobra.Command, f output.Formatter, data any) {
+	if err := f.Fprint(cmd.OutOrStdout(), data); err != nil {
+		cmd.PrintErrf("Error printing result: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// rowWriter prints rows with a formatter. Rows are written as they are added for
+// ndjson, so long outputs stream, and collected for formatters that need every row at once.
+type rowWriter[T any] struct {
+	cmd  *cobra.Command
+	f    output.Formatter
+	rows []T
+}
+
+func newRowWriter[T any](cmd *cobra.Command, f output.Formatter) *rowWriter[T] {
+	return &rowWriter[T]{cmd: cmd, f: f, rows: []T{}}
+}
+
+// streams reports whether rows are written as they are added.
+func (w *rowWriter[T]) streams() bool {
+	_, ok := w.f.(*output.NDJSONFormatter)
+	return ok
+}
+
+func (w *rowWriter[T]) add(row T) {
+	if w.streams() {
+		printResult(w.cmd, w.f, row)
+		return
+	}
+	w.rows = append(w.rows, row)
+}
+
+// flush prints the collected rows.
+func (w *rowWriter[T]) flush() {
+	if !w.streams() {
+		printResult(w.cmd, w.f, w.rows)
+	}
+}
+
+// prefixOutput describes one prefix of a set, such as the result of merge or exclude.
+type prefixOutput struct {
+	Prefix    string   `json:"prefix" tabs:"Prefix"`
+	First     string   `json:"first" tabs:"First"`
+	Last      string   `json:"last" tabs:"Last"`
+	Addresses *big.Int `json:"addresses" tabs:"Addresses"`
+}
+
+func prefixOutputs(ps []netip.Prefix) []prefixOutput {
+	o := make([]prefixOutput, len(ps))
+	for i, p := range ps {
+		r := network.PrefixRange(p)
+		o[i] = prefixOutput{
+			Prefix:    p.Masked().String(),
+			First:     r.First.String(),
+			Last:      r.Last.String(),
+			Addresses: new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits())),
+		}
+	}
+	return o
+}
+
+// printPrefixes prints a set of prefixes, one per line or with the chosen formatter.
+func printPrefixes(cmd *cobra.Command, ps []netip.Prefix) {
+	if f, ok := outputFormatter(cmd); ok {
+		printResult(cmd, f, prefixOutputs(ps))
+		return
+	}
+	for _, p := range ps {
+		cmd.Println(p.String())
+	}
+}
+
+// subnetOutput describes a subnet of a divided network.
+type subnetOutput struct {
+	Network   string        `json:"network" tabs:"Network"`
+	Subnet    string        `json:"subnet" tabs:"Subnet"`
+	Base      string        `json:"baseAddress" tabs:"Base address"`
+	Usable    network.Range `json:"usable" tabs:"Usable range"`
+	Addresses *big.Int      `json:"addresses" tabs:"Addresses"`
+}
+
+func newSubnetOutput(parent string, p netip.Prefix) subnetOutput {
+	n, _ := network.New(p.String()) // p is a valid prefix
+	return subnetOutput{
+		Network:   parent,
+		Subnet:    p.String(),
+		Base:      n.BaseAddress().String(),
+		Usable:    network.Range{First: n.FirstUsableAddress(), Last: n.LastUsableAddress()},
+		Addresses: n.Count(),
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(overlapCmd)
+	addInputFlags(overlapCmd, "CIDRs")
+	addOutputFlag(overlapCmd, output.DefaultFormat)
+}
+
+type overlapOutput struct {
+	Network     string           `json:"network" tabs:"Network"`
+	Source      string           `json:"source" tabs:"Source"`
+	Relation    network.Relation `json:"relation" tabs:"Relation"`
+	Other       string           `json:"other" tabs:"Other"`
+	OtherSource string           `json:"otherSource" tabs:"Other source"`
+	Shared      network.Range    `json:"shared" tabs:"Shared range"`
+}
+
+var overlapCmd = &cobra.Command{
+	Use:   "overlap",
+	Short: "Report overlapping networks",
+	Long: `Overlap checks a list of CIDRs and reports every pair that shares addresses,
+whether one contains the other or both are equal, and the shared range.
+CIDRs are read from the arguments, from files given with --file, or from stdin when neither is given.
+The command exits with status 2 when overlaps are found, so it can be used to gate CI.`,
+	Aliases: []string{"o", "conflicts"},
+	Example: `cidr overlap 10.0.0.0/16 10.0.128.0/20 192.168.0.0/24
+cidr overlap -f vpcs.txt -o json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f, _ := outputFormatter(cmd)
+
+		// Every line must be valid, as a skipped network could hide an overlap.
+		prefixes, lines, ok := parseLines(cmd, readLines(cmd, args), parsePrefix)
+		if !ok {
+			os.Exit(1)
+		}
+
+		overlaps := network.Overlaps(prefixes)
+		if len(overlaps) == 0 {
+			// The table would be empty, but a chosen format still gets an empty result
+			// so scripts parsing stdout see one.
+			if name, _ := cmd.Flags().GetString("out"); name == output.DefaultFormat {
+				cmd.PrintErrln("No overlapping networks found")
+			} else {
+				printResult(cmd, f, []overlapOutput{})
+			}
+			return
+		}
+
+		o := make([]overlapOutput, len(overlaps))
+		for i, ov := range overlaps {
+			o[i] = overlapOutput{
+				Network:     prefixes[ov.A].String(),
+				Source:      lines[ov.A].String(),
+				Relation:    ov.Relation,
+				Other:       prefixes[ov.B].String(),
+				OtherSource: lines[ov.B].String(),
+				Shared:      ov.Shared,
+			}
+		}
+		printResult(cmd, f, o)
+		os.Exit(2)
+	},
+}
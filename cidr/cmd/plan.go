@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"math/big"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/jokarl/go-learning-projects/cidr/plan"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(planCmd)
+	planCmd.AddCommand(planApplyCmd)
+	addOutputFlag(planCmd, output.DefaultFormat)
+}
+
+// planRowOutput is one block or free range of an applied plan.
+// Tree is the name indented by depth, for reading the hierarchy in a table.
+// Free rows are named "(free)" and have the path of the block they are free in.
+type planRowOutput struct {
+	Tree      string        `json:"-" tabs:"Name"`
+	Name      string        `json:"name" tabs:"-"`
+	Path      string        `json:"path" tabs:"-"`
+	Depth     int           `json:"depth" tabs:"-"`
+	Status    string        `json:"status" tabs:"Status"`
+	Prefix    string        `json:"prefix" tabs:"Prefix"`
+	Range     network.Range `json:"range" tabs:"Range"`
+	Hosts     *int          `json:"hosts,omitempty" tabs:"Hosts"`
+	Addresses *big.Int      `json:"addresses" tabs:"Addresses"`
+}
+
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Allocate hierarchical address plans",
+}
+
+var planApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Allocate the blocks of a plan file",
+	Long: `Apply reads a plan file in YAML or JSON and allocates its blocks recursively.
+The top-level block has a cidr; every nested block has a name and either a number of hosts or a prefix length,
+and may contain blocks of its own. Blocks are placed inside their parent largest first, using the best-fit
+strategy of VLSM, and the command fails with the path of the first block that does not fit.
+The result lists every block in plan order, followed by the space left free at its level.
+
+Example plan:
+  name: corp
+  cidr: 10.0.0.0/8
+  blocks:
+    - name: eu-west-1
+      prefix: /16
+      blocks:
+        - name: az-a
+          prefix: /20
+          blocks:
+            - name: web
+              hosts: 200
+            - name: db
+              hosts: 50`,
+	Example: `cidr plan apply plan.yaml
+cidr plan apply plan.json -o csv`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr plan apply <plan file>")
+			os.Exit(1)
+		}
+		f, _ := outputFormatter(cmd)
+
+		root, err := plan.Load(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		tree, err := plan.Apply(root)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		printResult(cmd, f, planRows(tree, 0, nil))
+	},
+}
+
+// planRows flattens the tree depth first: each block is followed by its children
+// and then by the free space left inside it.
+func planRows(n *plan.Node, depth int, rows []planRowOutput) []planRowOutput {
+	indent := strings.Repeat("  ", depth)
+	var hosts *int
+	if n.Hosts > 0 {
+		hosts = &n.Hosts
+	}
+	rows = append(rows, planRowOutput{
+		Tree:      indent + n.Name,
+		Name:      n.Name,
+		Path:      n.Path,
+		Depth:     depth,
+		Status:    "allocated",
+		Prefix:    n.Prefix.String(),
+		Range:     network.PrefixRange(n.Prefix),
+		Hosts:     hosts,
+		Addresses: network.Size([]netip.Prefix{n.Prefix}),
+	})
+	for _, c := range n.Children {
+		rows = planRows(c, depth+1, rows)
+	}
+	// Free space is only interesting where the plan divides a block.
+	if len(n.Children) == 0 {
+		return rows
+	}
+	for _, p := range n.Free {
+		rows = append(rows, planRowOutput{
+			Tree:      indent + "  (free)",
+			Name:      "(free)",
+			Path:      n.Path,
+			Depth:     depth + 1,
+			Status:    "free",
+			Prefix:    p.String(),
+			Range:     network.PrefixRange(p),
+			Addresses: network.Size([]netip.Prefix{p}),
+		})
+	}
+	return rows
+}
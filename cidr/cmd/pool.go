@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"math/big"
+	"net/netip"
+	"os"
+	"sort"
+
+	"github.com/jokarl/go-learning-projects/cidr/ipam"
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ipamStatePath string
+	poolName      string
+)
+
+func init() {
+	rootCmd.AddCommand(poolCmd)
+	poolCmd.AddCommand(poolCreateCmd, poolListCmd, poolShowCmd, poolDeleteCmd)
+
+	addStateFlag(poolCmd)
+	addOutputFlag(poolCmd, output.DefaultFormat)
+	poolCreateCmd.Flags().StringVarP(&poolName, "name", "n", "", "Name of the pool (defaults to the CIDR)")
+}
+
+// addStateFlag registers the --state flag shared by the IPAM commands.
+func addStateFlag(c *cobra.Command) {
+	c.PersistentFlags().StringVar(&ipamStatePath, "state", ipam.DefaultPath(), "Path to the IPAM state file (or set CIDR_IPAM_STATE)")
+}
+
+type poolOutput struct {
+	Name          string       `json:"name" tabs:"Name"`
+	Prefix        netip.Prefix `json:"prefix" tabs:"Prefix"`
+	Allocations   int          `json:"allocations" tabs:"Allocations"`
+	FreeAddresses *big.Int     `json:"freeAddresses" tabs:"Free addresses"`
+	LargestFree   netip.Prefix `json:"largestFree" tabs:"Largest free block,omitempty"`
+}
+
+type poolBlockOutput struct {
+	Prefix  netip.Prefix  `json:"prefix" tabs:"Prefix"`
+	Range   network.Range `json:"range" tabs:"Range"`
+	Status  string        `json:"status" tabs:"Status"`
+	Owner   string        `json:"owner,omitempty" tabs:"Owner"`
+	Created string        `json:"created,omitempty" tabs:"Created"`
+}
+
+var poolCmd = &cobra.Command{
+	Use:   "pool",
+	Short: "Manage IPAM pools that blocks are allocated from",
+	Long: `Pool manages the address pools of the local IPAM state file.
+Blocks are handed out from a pool with "cidr alloc" and returned with "cidr release".`,
+	Aliases: []string{"p"},
+}
+
+var poolCreateCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create a new pool",
+	Example: "cidr pool create 10.0.0.0/8 --name corp",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr pool create <CIDR> [--name <name>]")
+			os.Exit(1)
+		}
+
+		prefix, err := parsePrefix(args[0])
+		if err != nil {
+			cmd.PrintErrf("Invalid CIDR: %s\n", err)
+			os.Exit(1)
+		}
+		name := poolName
+		if name == "" {
+			name = prefix.Masked().String()
+		}
+
+		err = ipam.NewStore(ipamStatePath).Update(func(s *ipam.State) error {
+			_, err := s.CreatePool(name, prefix)
+			return err
+		})
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		cmd.Printf("Created pool %s (%s)\n", name, prefix.Masked())
+	},
+}
+
+var poolListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List all pools",
+	Aliases: []string{"ls"},
+	Example: "cidr pool list",
+	Run: func(cmd *cobra.Command, args []string) {
+		f, _ := outputFormatter(cmd)
+		s, err := ipam.NewStore(ipamStatePath).Load()
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		o := make([]poolOutput, len(s.Pools))
+		for i, p := range s.Pools {
+			free := p.Free()
+			var largest netip.Prefix
+			for _, b := range free {
+				if !largest.IsValid() || b.Bits() < largest.Bits() {
+					largest = b
+				}
+			}
+			o[i] = poolOutput{
+				Name:          p.Name,
+				Prefix:        p.Prefix,
+				Allocations:   len(p.Allocations),
+				FreeAddresses: network.Size(free),
+				LargestFree:   largest,
+			}
+		}
+		printResult(cmd, f, o)
+	},
+}
+
+var poolShowCmd = &cobra.Command{
+	Use:     "show",
+	Short:   "Show the allocations and free space of a pool",
+	Example: "cidr pool show corp",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr pool show <name>")
+			os.Exit(1)
+		}
+		f, _ := outputFormatter(cmd)
+		s, err := ipam.NewStore(ipamStatePath).Load()
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		p, err := s.Pool(args[0])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		var o []poolBlockOutput
+		for _, a := range p.Allocations {
+			o = append(o, poolBlockOutput{
+				Prefix:  a.Prefix,
+				Range:   network.PrefixRange(a.Prefix),
+				Status:  "allocated",
+				Owner:   a.Owner,
+				Created: a.Created.Format("2006-01-02 15:04:05"),
+			})
+		}
+		for _, b := range p.Free() {
+			o = append(o, poolBlockOutput{Prefix: b, Range: network.PrefixRange(b), Status: "free"})
+		}
+		sort.Slice(o, func(i, j int) bool { return o[i].Prefix.Addr().Less(o[j].Prefix.Addr()) })
+		printResult(cmd, f, o)
+	},
+}
+
+var poolDeleteCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete a pool without allocations",
+	Aliases: []string{"rm"},
+	Example: "cidr pool delete corp",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			cmd.PrintErrln("Usage: cidr pool delete <name>")
+			os.Exit(1)
+		}
+		err := ipam.NewStore(ipamStatePath).Update(func(s *ipam.State) error {
+			return s.DeletePool(args[0])
+		})
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		cmd.Printf("Deleted pool %s\n", args[0])
+	},
+}
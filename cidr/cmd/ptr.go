@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ptrRFC2317    bool
+	ptrZoneFile   bool
+	ptrHostname   string
+	ptrNS         []string
+	ptrEmail      string
+	ptrTTL        int
+	ptrMaxRecords uint64
+)
+
+func init() {
+	rootCmd.AddCommand(ptrCmd)
+	ptrCmd.Flags().BoolVar(&ptrRFC2317, "rfc2317", false, "Use RFC 2317 classless delegation for IPv4 prefixes longer than /24")
+	ptrCmd.Flags().BoolVarP(&ptrZoneFile, "zone-file", "z", false, "Print a BIND style zone file skeleton with PTR records")
+	ptrCmd.Flags().StringVar(&ptrHostname, "hostname", "host-{ip}.example.com.", "Hostname pattern for PTR records; {ip} is the address with dashes, {1}-{4} are the v4 octets, {n} is the host number")
+	ptrCmd.Flags().StringSliceVar(&ptrNS, "ns", []string{"ns1.example.com."}, "Name servers for the NS records")
+	ptrCmd.Flags().StringVar(&ptrEmail, "email", "hostmaster.example.com.", "Responsible mailbox for the SOA record, in DNS notation")
+	ptrCmd.Flags().IntVar(&ptrTTL, "ttl", 3600, "Default TTL of the zone file")
+	ptrCmd.Flags().Uint64Var(&ptrMaxRecords, "max-records", 65536, "Maximum number of PTR records in a zone file")
+	addInputFlags(ptrCmd, "CIDRs")
+	addOutputFlag(ptrCmd, "")
+}
+
+// ptrOutput is a reverse zone of a network. Classless zones also name
+// the parent zone they are delegated from.
+type ptrOutput struct {
+	Network string `json:"network" tabs:"Network"`
+	Zone    string `json:"zone" tabs:"Zone"`
+	Parent  string `json:"parentZone,omitempty" tabs:"Parent zone,omitempty"`
+}
+
+var ptrCmd = &cobra.Command{
+	Use:   "ptr",
+	Short: "Print the reverse DNS zones of a CIDR network",
+	Long: `Ptr prints the in-addr.arpa (v4) or ip6.arpa (v6) zones needed to delegate reverse DNS for a network.
+Zones fall on octet (v4) or nibble (v6) boundaries, so other prefixes are split into several zones.
+IPv4 networks longer than /24 can be delegated with RFC 2317 classless delegation using --rfc2317,
+which prints the classless zone and the CNAME and NS records to add to the parent zone.
+With --zone-file, a BIND style zone file skeleton with PTR records for the usable addresses is printed.
+With -o, every zone is a row; a zone file is its own format, so --zone-file cannot be combined with -o.`,
+	Example: `cidr ptr 10.0.0.0/22
+cidr ptr 2001:db8::/46
+cidr ptr --rfc2317 192.0.2.64/26
+cidr ptr --zone-file --hostname "{ip}.hosts.example.com." 192.0.2.0/28`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		lines := readLines(cmd, args)
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr ptr <CIDR> [<CIDR> ...]")
+			os.Exit(1)
+		}
+		if len(ptrNS) == 0 || slices.Contains(ptrNS, "") {
+			cmd.PrintErrln("--ns needs at least one name server, and names cannot be empty")
+			os.Exit(1)
+		}
+		f, structured := outputFormatter(cmd)
+		if structured && ptrZoneFile {
+			cmd.PrintErrln("--zone-file cannot be combined with -o")
+			os.Exit(1)
+		}
+		prefixes, _, ok := parseLines(cmd, lines, parsePrefix)
+
+		if structured {
+			o, err := ptrOutputs(prefixes)
+			if err != nil {
+				cmd.PrintErrf("Error: %s\n", err)
+				os.Exit(1)
+			}
+			printResult(cmd, f, o)
+			if !ok {
+				os.Exit(1)
+			}
+			return
+		}
+
+		w := bufio.NewWriter(cmd.OutOrStdout())
+		defer w.Flush()
+
+		for _, p := range prefixes {
+			var err error
+			p = p.Masked()
+
+			classless := ptrRFC2317 && p.Addr().Is4() && p.Bits() > 24
+			if ptrZoneFile {
+				err = writeZoneFiles(w, p, classless)
+			} else if classless {
+				err = writeClassless(w, p)
+			} else {
+				for _, z := range network.ReverseZones(p) {
+					_, err = fmt.Fprintln(w, network.ZoneName(z))
+				}
+			}
+			if err != nil {
+				_ = w.Flush()
+				cmd.PrintErrf("Error: %s\n", err)
+				os.Exit(1)
+			}
+		}
+		if !ok {
+			_ = w.Flush()
+			os.Exit(1)
+		}
+	},
+}
+
+// ptrOutputs returns the reverse zones of every prefix, or its classless zone with --rfc2317.
+func ptrOutputs(prefixes []netip.Prefix) ([]ptrOutput, error) {
+	o := []ptrOutput{}
+	for _, p := range prefixes {
+		p = p.Masked()
+		if ptrRFC2317 && p.Addr().Is4() && p.Bits() > 24 {
+			zone, err := network.ClasslessZoneName(p)
+			if err != nil {
+				return nil, err
+			}
+			o = append(o, ptrOutput{Network: p.String(), Zone: zone, Parent: network.ZoneName(network.ReverseZones(p)[0])})
+			continue
+		}
+		for _, z := range network.ReverseZones(p) {
+			o = append(o, ptrOutput{Network: p.String(), Zone: network.ZoneName(z)})
+		}
+	}
+	return o, nil
+}
+
+// writeClassless prints the RFC 2317 zone of p and the records that delegate it from the parent zone.
+func writeClassless(w io.Writer, p netip.Prefix) error {
+	zone, err := network.ClasslessZoneName(p)
+	if err != nil {
+		return err
+	}
+	parent := network.ZoneName(network.ReverseZones(p)[0])
+
+	fmt.Fprintf(w, "; Classless zone for %s\n%s\n\n", p, zone)
+	fmt.Fprintf(w, "; Records for the parent zone %s\n", parent)
+	for _, ns := range ptrNS {
+		fmt.Fprintf(w, "%s\tIN\tNS\t%s\n", zone, ns)
+	}
+	n, _ := network.New(p.String())
+	for a := range n.Hosts(false, 0, 1) {
+		owner, target, err := network.ClasslessCNAME(p, a)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\tIN\tCNAME\t%s\n", owner, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeZoneFiles prints a zone file skeleton for every reverse zone of p,
+// with PTR records for the usable addresses of p that fall into the zone.
+func writeZoneFiles(w io.Writer, p netip.Prefix, classless bool) error {
+	n, _ := network.New(p.String())
+	if count := n.Count(); !count.IsUint64() || count.Uint64() > ptrMaxRecords {
+		return fmt.Errorf("%s has %s addresses, more than --max-records %d", p, count, ptrMaxRecords)
+	}
+
+	zones := network.ReverseZones(p)
+	names := make([]string, len(zones))
+	for i, z := range zones {
+		names[i] = network.ZoneName(z)
+	}
+	if classless {
+		zone, err := network.ClasslessZoneName(p)
+		if err != nil {
+			return err
+		}
+		names = []string{zone}
+	}
+
+	zi := -1
+	var index uint64
+	for a := range n.Hosts(true, 0, 1) {
+		index++
+		name := network.ReverseName(a)
+		if classless {
+			_, name, _ = network.ClasslessCNAME(p, a)
+		}
+		// Addresses are visited in order, so a new zone starts when
+		// the name no longer belongs to the current one.
+		for zi < 0 || !strings.HasSuffix(name, "."+names[zi]) {
+			zi++
+			if zi == len(names) {
+				return fmt.Errorf("no zone found for %s", a)
+			}
+			writeZoneHeader(w, names[zi])
+		}
+		owner := strings.TrimSuffix(name, "."+names[zi])
+		if _, err := fmt.Fprintf(w, "%s\tIN\tPTR\t%s\n", owner, hostname(ptrHostname, a, index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeZoneHeader(w io.Writer, zone string) {
+	fmt.Fprintf(w, "\n$ORIGIN %s\n$TTL %d\n", zone, ptrTTL)
+	fmt.Fprintf(w, "@\tIN\tSOA\t%s %s (\n", ptrNS[0], ptrEmail)
+	fmt.Fprintf(w, "\t\t1\t; serial\n\t\t%d\t; refresh\n\t\t900\t; retry\n\t\t604800\t; expire\n\t\t%d )\t; negative caching TTL\n", ptrTTL, ptrTTL)
+	for _, ns := range ptrNS {
+		fmt.Fprintf(w, "@\tIN\tNS\t%s\n", ns)
+	}
+}
+
+// hostname expands the placeholders of pattern for address a, the n-th usable address.
+func hostname(pattern string, a netip.Addr, n uint64) string {
+	ip := a.String()
+	if a.Is6() {
+		ip = a.StringExpanded()
+	}
+	r := []string{
+		"{ip}", strings.NewReplacer(".", "-", ":", "-").Replace(ip),
+		"{n}", strconv.FormatUint(n, 10),
+	}
+	if a.Is4() {
+		b := a.As4()
+		for i := range b {
+			r = append(r, "{"+strconv.Itoa(i+1)+"}", strconv.Itoa(int(b[i])))
+		}
+	}
+	return strings.NewReplacer(r...).Replace(pattern)
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// A /23 needs two /24 zones, and with --rfc2317 a /26 gets its classless zone
+// plus the /24 zone its CNAMEs go in.
+func TestPtrOutputs(t *testing.T) {
+	// arrange
+	defer func(v bool) { ptrRFC2317 = v }(ptrRFC2317)
+	ptrRFC2317 = true
+	prefixes := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/23"), netip.MustParsePrefix("192.0.2.64/26")}
+
+	// act
+	got, err := ptrOutputs(prefixes)
+
+	// assert
+	if err != nil {
+		t.Fatalf(`ptrOutputs returned error: %s`, err)
+	}
+	want := []ptrOutput{
+		{Network: "10.0.0.0/23", Zone: "0.0.10.in-addr.arpa."},
+		{Network: "10.0.0.0/23", Zone: "1.0.10.in-addr.arpa."},
+		{Network: "192.0.2.64/26", Zone: "64/26.2.0.192.in-addr.arpa.", Parent: "2.0.192.in-addr.arpa."},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf(`ptrOutputs = %+v, want %+v`, got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+var rangeMerge bool
+
+func init() {
+	rootCmd.AddCommand(rangeCmd)
+	addInputFlags(rangeCmd, "ranges or CIDRs")
+	rangeCmd.Flags().BoolVarP(&rangeMerge, "merge", "m", false, "Merge all ranges and CIDRs into the smallest list of contiguous ranges")
+	addOutputFlag(rangeCmd, "")
+}
+
+// rangeOutput is one converted line. Merged ranges have no input or prefix.
+type rangeOutput struct {
+	Input  string `json:"input,omitempty" tabs:"Input"`
+	Prefix string `json:"prefix,omitempty" tabs:"Prefix"`
+	First  string `json:"first" tabs:"First"`
+	Last   string `json:"last" tabs:"Last"`
+}
+
+var rangeCmd = &cobra.Command{
+	Use:   "range",
+	Short: "Convert address ranges to CIDRs and back",
+	Long: `Range converts an inclusive address range such as "10.0.0.5-10.0.0.20" into the smallest
+list of CIDRs covering it, and prints the first and last address of a CIDR.
+Input is read from the arguments, from files given with --file, or from stdin when neither is given.
+Ranges may be written as "first-last", "first - last" (quoted) or "first last" on a line of its own;
+an address followed by a netmask or wildcard mask is read as that CIDR instead.
+With --merge, every range and CIDR is read first and only the merged ranges are printed.`,
+	Aliases: []string{"r"},
+	Example: `cidr range 10.0.0.5-10.0.0.20
+cidr range 10.0.0.0/24 2001:db8::/64
+cidr range --merge -f allowlist.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lines := readLines(cmd, args)
+		f, structured := outputFormatter(cmd)
+
+		// Results are printed as they are found, or collected for the formatter.
+		// An empty result is still printed as an empty list.
+		o := []rangeOutput{}
+		emit := func(r rangeOutput, text string) {
+			if structured {
+				o = append(o, r)
+				return
+			}
+			cmd.Println(text)
+		}
+
+		// With --merge, every line is collected first and only the merged ranges are printed.
+		var merge []netip.Prefix
+		failed := false
+		for _, l := range lines {
+			ps, isRange, err := parseRangeLine(l.Text)
+			if err != nil {
+				cmd.PrintErrf("Error: %s: %s\n", l, err)
+				failed = true
+				continue
+			}
+			if rangeMerge {
+				merge = append(merge, ps...)
+				continue
+			}
+			if isRange {
+				for _, p := range ps {
+					pr := network.PrefixRange(p)
+					emit(rangeOutput{Input: l.Text, Prefix: p.String(), First: pr.First.String(), Last: pr.Last.String()}, p.String())
+				}
+				continue
+			}
+			r := network.PrefixRange(ps[0])
+			emit(rangeOutput{Input: l.Text, Prefix: ps[0].Masked().String(), First: r.First.String(), Last: r.Last.String()}, r.String())
+		}
+
+		for _, r := range network.Ranges(merge) {
+			emit(rangeOutput{First: r.First.String(), Last: r.Last.String()}, r.String())
+		}
+		if structured {
+			printResult(cmd, f, o)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// parseRangeLine parses a line of range input. A range is returned as the prefixes covering it
+// and isRange true; any other notation parsePrefix accepts is returned as its single prefix.
+// "10.1.0.0 255.255.252.0" is read as a netmask rather than a "first last" range,
+// so a second field is only taken as the last address if it is not a mask.
+func parseRangeLine(s string) (prefixes []netip.Prefix, isRange bool, err error) {
+	if !strings.Contains(s, "-") {
+		p, err := parsePrefix(s)
+		if err == nil {
+			return []netip.Prefix{p}, false, nil
+		}
+		if len(strings.Fields(s)) != 2 {
+			return nil, false, err
+		}
+	}
+	r, err := network.ParseRange(s)
+	if err != nil {
+		return nil, false, err
+	}
+	return r.Prefixes(), true, nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+// The notations that look alike: "first last" ranges
+// against an address followed by a netmask or wildcard, which is a single CIDR.
+func TestParseRangeLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		isRange bool
+	}{
+		{"10.1.0.0 255.255.252.0", []string{"10.1.0.0/22"}, false},
+		{"192.168.10.0 255.255.255.0", []string{"192.168.10.0/24"}, false},
+		{"10.1.0.0 0.0.3.255", []string{"10.1.0.0/22"}, false},
+		{"10.0.0.1", []string{"10.0.0.1/32"}, false},
+		{"10.0.0.0/30", []string{"10.0.0.0/30"}, false},
+		{"10.0.0.4 10.0.0.11", []string{"10.0.0.4/30", "10.0.0.8/30"}, true},
+		{"10.0.0.4-10.0.0.11", []string{"10.0.0.4/30", "10.0.0.8/30"}, true},
+		{"2001:db8::  2001:db8::3", []string{"2001:db8::/126"}, true},
+	}
+	for _, tt := range tests {
+		ps, isRange, err := parseRangeLine(tt.line)
+		if err != nil {
+			t.Errorf(`parseRangeLine(%q) returned error: %s`, tt.line, err)
+			continue
+		}
+		got := make([]string, len(ps))
+		for i, p := range ps {
+			got[i] = p.String()
+		}
+		if !slices.Equal(got, tt.want) || isRange != tt.isRange {
+			t.Errorf(`parseRangeLine(%q) = %v, %t, want %v, %t`, tt.line, got, isRange, tt.want, tt.isRange)
+		}
+	}
+	for _, line := range []string{"bad", "10.0.0.11 10.0.0.4", "10.0.0.0 255.0.255.0 x"} {
+		if _, _, err := parseRangeLine(line); err == nil {
+			t.Errorf(`parseRangeLine(%q) returned no error`, line)
+		}
+	}
+}
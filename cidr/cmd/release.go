@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/ipam"
+	"github.com/spf13/cobra"
+)
+
+var releasePool string
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	addStateFlag(releaseCmd)
+	releaseCmd.Flags().StringVarP(&releasePool, "pool", "p", "", "Name of the pool the blocks were allocated from")
+	if err := releaseCmd.MarkFlagRequired("pool"); err != nil {
+		os.Exit(1)
+	}
+	addOutputFlag(releaseCmd, "")
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Return allocated blocks to a pool",
+	Long: `Release removes allocations from the IPAM state file.
+The freed space is merged with adjacent free blocks, so it can be handed out as larger blocks again.`,
+	Aliases: []string{"free"},
+	Example: "cidr release --pool corp 10.0.3.0/24",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			cmd.PrintErrln("Usage: cidr release --pool <name> <CIDR> [<CIDR> ...]")
+			os.Exit(1)
+		}
+
+		var released []ipam.Allocation
+		err := ipam.NewStore(ipamStatePath).Update(func(s *ipam.State) error {
+			p, err := s.Pool(releasePool)
+			if err != nil {
+				return err
+			}
+			for _, arg := range args {
+				prefix, err := parsePrefix(arg)
+				if err != nil {
+					return err
+				}
+				a, err := p.Release(prefix)
+				if err != nil {
+					return err
+				}
+				released = append(released, a)
+			}
+			return nil
+		})
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if f, ok := outputFormatter(cmd); ok {
+			o := make([]allocationOutput, len(released))
+			for i, a := range released {
+				o[i] = newAllocationOutput(releasePool, a)
+			}
+			printResult(cmd, f, o)
+			return
+		}
+		for _, a := range released {
+			cmd.Printf("Released %s (owner %s)\n", a.Prefix, a.Owner)
+		}
+	},
+}
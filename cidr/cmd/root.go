@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/jokarl/go-learning-projects/cidr/output"
 	"github.com/spf13/cobra"
 )
 
@@ -10,8 +11,24 @@ import (
 var rootCmd = &cobra.Command{
 	Use:   "cidr",
 	Short: "A terminal application for working with CIDR notations",
+	Long: `A terminal application for working with CIDR notations.
+
+Besides CIDRs, networks can be written with a dotted netmask (10.1.0.0 255.255.252.0),
+a Cisco wildcard mask (10.1.0.0 0.0.3.255), as a bare address (10.1.0.1 is a /32),
+with an IPv6 zone ID (fe80::1%eth0/64) or as a range (10.1.0.0-10.1.3.255).
+Host bits are masked, unless --strict is given.
+
+Colours are left out when stdout is not a terminal, NO_COLOR is set or --no-color is given.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if noColor {
+			output.SetColor(false)
+		}
+	},
 }
 
+// noColor is set with --no-color.
+var noColor bool
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -22,4 +39,9 @@ func Execute() {
 }
 
 func init() {
+	// cmd.Print* writes to stderr unless told otherwise.
+	// Results belong on stdout so they can be piped into other tools.
+	rootCmd.SetOut(os.Stdout)
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Print without colours")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Reject CIDRs with host bits set instead of masking them")
 }
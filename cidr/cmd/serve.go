@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	serveListen  string
+	serveOpenAPI bool
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&serveListen, "listen", "l", ":8080", "Address to listen on")
+	serveCmd.Flags().BoolVar(&serveOpenAPI, "openapi", false, "Print the OpenAPI document and exit")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the calculator as a JSON API",
+	Long: `Serve exposes explain, count, contains, divide, vlsm, embed and merge as JSON endpoints,
+so they can be called over HTTP instead of running the command. Every endpoint takes a POST
+with a JSON body under /v1/, e.g. /v1/count with {"networks": ["10.0.0.0/16"]}, and returns
+the same JSON as the command does with -o json, always as a list.
+Errors are returned as {"error": "...", "details": [{"input": "...", "error": "..."}]},
+with status 400 for invalid input, 413 for bodies over 1 MiB and 422 for requests that cannot be carried out,
+including divide and vlsm requests that would list more than 1048576 subnets.
+The OpenAPI document is served at /openapi.json, or printed with --openapi.
+--strict applies to every request.`,
+	Example: `cidr serve --listen :8080
+curl -s localhost:8080/v1/count -d '{"networks": ["10.0.0.0/16"]}'
+cidr serve --openapi > openapi.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if serveOpenAPI {
+			f, _ := output.GetFormatter("json")
+			printResult(cmd, f, openAPIDocument(apiEndpoints))
+			return
+		}
+
+		srv := &http.Server{
+			Addr:              serveListen,
+			Handler:           newAPIHandler(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		cmd.PrintErrf("Listening on %s\n", serveListen)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+
+	"github.com/jokarl/go-learning-projects/cidr/shell"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
+
+// takesNetwork is the annotation of commands that take a network as their first argument,
+// so the shell passes them the current one.
+const takesNetwork = "cidr/takes-network"
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [CIDR]",
+	Short: "Explore a network interactively",
+	Long: `Shell starts an interactive mode that keeps a current network. Split it with "split 4",
+make a subnet current with "select 2", go back with "up", and see where you are in the prompt.
+Other cidr commands run exactly as on the command line, with the current network as their
+first argument where they take one, e.g. "explain" or "contains 10.0.3.7".
+Lines can be edited, earlier lines recalled with the arrow keys and commands completed with Tab.
+Type "help" for the list of commands.`,
+	Example: `cidr shell 10.0.0.0/16`,
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		root := ""
+		if len(args) == 1 {
+			root = args[0]
+		}
+
+		globals := globalFlags(cmd)
+		run := func(args []string) error {
+			return runSelf(slices.Concat(globals, args))
+		}
+		s, err := shell.NewSession(root, shellCommands(cmd), run, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if err := s.Run(shell.NewEditor(os.Stdin, cmd.OutOrStdout())); err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+// shellCommands returns the cidr commands the shell can run, leaving out the shell itself.
+func shellCommands(self *cobra.Command) []shell.Command {
+	var commands []shell.Command
+	for _, c := range rootCmd.Commands() {
+		if c == self || !c.IsAvailableCommand() || c.Name() == "completion" || c.Name() == "serve" {
+			continue
+		}
+		commands = append(commands, shell.Command{Name: c.Name(), Aliases: c.Aliases, TakesNetwork: c.Annotations[takesNetwork] == "true"})
+	}
+	return commands
+}
+
+// globalFlags returns the persistent flags the shell was started with, such as --strict,
+// so the commands it runs apply them too.
+func globalFlags(cmd *cobra.Command) []string {
+	var args []string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if rootCmd.PersistentFlags().Lookup(f.Name) != nil {
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+	return args
+}
+
+// runSelf runs a cidr command line in a new process, so a command that exits on errors
+// does not end the shell and no flags are left over from earlier commands.
+// Its stdin is empty, so commands never wait for input the shell would otherwise read.
+func runSelf(args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	c := exec.Command(self, args...)
+	c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil // the command has reported its own error
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jokarl/go-learning-projects/cidr/shell"
+)
+
+// Only commands whose first argument is a network get the current one in the shell.
+func TestShellCommands(t *testing.T) {
+	for name, want := range map[string]bool{"explain": true, "map": true, "vlsm": true, "diff": false, "merge": false, "range": false, "complement": false} {
+		c, _, err := rootCmd.Find([]string{name})
+		if err != nil {
+			t.Fatalf(`Find(%s) returned error: %s`, name, err)
+		}
+		got := c.Annotations[takesNetwork] == "true"
+		if got != want {
+			t.Errorf(`%s takes a network = %t, want %t`, name, got, want)
+		}
+	}
+}
+
+// Only persistent flags that were set are passed on to the commands the shell runs.
+func TestGlobalFlags(t *testing.T) {
+	// arrange
+	defer func() { strict, noColor = false, false }()
+	if err := shellCmd.ParseFlags([]string{"--strict"}); err != nil {
+		t.Fatalf(`ParseFlags returned error: %s`, err)
+	}
+
+	// act
+	got := globalFlags(shellCmd)
+
+	// assert
+	if want := []string{"--strict=true"}; !slices.Equal(got, want) {
+		t.Errorf(`globalFlags = %v, want %v`, got, want)
+	}
+}
+
+// range is run with a network selected. Its first argument is a range
+// rather than a network, so the current network must not be put in front of it.
+func TestShellRange(t *testing.T) {
+	var ran []string
+	run := func(args []string) error {
+		ran = append(ran, strings.Join(args, " "))
+		return nil
+	}
+	s, err := shell.NewSession("10.0.0.0/16", shellCommands(shellCmd), run, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"range 10.0.0.5-10.0.0.20", "explain"} {
+		if err := s.Exec(line); err != nil {
+			t.Fatalf(`Exec(%q) returned error: %s`, line, err)
+		}
+	}
+	if want := []string{"range 10.0.0.5-10.0.0.20", "explain 10.0.0.0/16"}; !slices.Equal(ran, want) {
+		t.Errorf(`ran %q, want %q`, ran, want)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(subnetCmd)
+	addOutputFlag(subnetCmd, "")
+}
+
+var subnetCmd = &cobra.Command{
+	Use:   "subnet",
+	Short: "Calculate a subnet like Terraform's cidrsubnet",
+	Long: `Subnet calculates a subnet address within a given network, with the same semantics and errors
+as Terraform's cidrsubnet(prefix, newbits, netnum) function. newbits is the number of bits to add
+to the prefix length, and netnum is the number of the subnet, counting from 0.`,
+	Aliases: []string{"cidrsubnet"},
+	Example: `cidr subnet 10.0.0.0/16 8 37
+cidr subnet 2001:db8::/32 16 255`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 3 {
+			cmd.PrintErrln("Usage: cidr subnet <CIDR> <newbits> <netnum>")
+			os.Exit(1)
+		}
+		newbits, err := parseTerraformInt("newbits", args[1])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		netnum, err := parseTerraformNumber("netnum", args[2])
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+
+		p, err := network.CIDRSubnet(args[0], newbits, netnum)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		if f, ok := outputFormatter(cmd); ok {
+			printResult(cmd, f, []subnetOutput{newSubnetOutput(args[0], p)})
+			return
+		}
+		cmd.Println(p.String())
+	},
+}
+
+// parseTerraformNumber parses a whole number argument of a Terraform function.
+func parseTerraformNumber(param, s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid value for %q parameter: a whole number is required", param)
+	}
+	return n, nil
+}
+
+// parseTerraformInt parses a number argument that must fit in an int, such as newbits.
+func parseTerraformInt(param, s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for %q parameter: a whole number is required", param)
+	}
+	return n, nil
+}
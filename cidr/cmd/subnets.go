@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(subnetsCmd)
+	addOutputFlag(subnetsCmd, "")
+}
+
+var subnetsCmd = &cobra.Command{
+	Use:   "subnets",
+	Short: "Allocate consecutive subnets like Terraform's cidrsubnets",
+	Long: `Subnets allocates consecutive subnets of a network, one for each newbits argument, with the same
+semantics and errors as Terraform's cidrsubnets(prefix, newbits...) function. Each subnet starts right
+after the previous one, aligned to its own size, so the order of the arguments matters.`,
+	Aliases: []string{"cidrsubnets"},
+	Example: `cidr subnets 10.1.0.0/16 4 4 8 4
+cidr subnets fd00:fd12:3456:7890::/56 16 16 16 32`,
+	Annotations: map[string]string{takesNetwork: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			cmd.PrintErrln("Usage: cidr subnets <CIDR> <newbits> [<newbits> ...]")
+			os.Exit(1)
+		}
+		newbits := make([]int, len(args)-1)
+		for i, arg := range args[1:] {
+			n, err := parseTerraformInt("newbits", arg)
+			if err != nil {
+				cmd.PrintErrf("Error: %s\n", err)
+				os.Exit(1)
+			}
+			newbits[i] = n
+		}
+
+		subnets, err := network.CIDRSubnets(args[0], newbits...)
+		if err != nil {
+			cmd.PrintErrf("Error: %s\n", err)
+			os.Exit(1)
+		}
+		printPrefixes(cmd, subnets)
+	},
+}
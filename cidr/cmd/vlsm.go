@@ -2,67 +2,308 @@ package cmd
 
 import (
 	"fmt"
+	"math/big"
+	"net/netip"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/jokarl/go-learning-projects/cidr/network"
 	"github.com/spf13/cobra"
 )
 
+var (
+	vlsmReserve    []string
+	vlsmInputOrder bool
+	vlsmNibble     bool
+)
+
 func init() {
 	rootCmd.AddCommand(vlsmCmd)
+	vlsmCmd.Flags().StringSliceVarP(&vlsmReserve, "reserve", "r", nil, "CIDRs already in use, which are never allocated (repeatable or comma separated)")
+	vlsmCmd.Flags().BoolVar(&vlsmInputOrder, "input-order", false, "Place and list subnets in the order requested instead of largest first")
+	vlsmCmd.Flags().BoolVar(&vlsmNibble, "nibble", false, "Align v6 allocations to 4-bit boundaries, so each can be delegated as a reverse DNS zone")
+	addInputFlags(vlsmCmd, "host counts or subnet requests")
+	addOutputFlag(vlsmCmd, "")
+}
+
+// vlsmOutput describes an allocated, reserved or leftover subnet of a VLSM plan.
+type vlsmOutput struct {
+	Name        string        `json:"name,omitempty" tabs:"Name"`
+	Subnet      string        `json:"subnet" tabs:"Subnet"`
+	Status      string        `json:"status" tabs:"Status"`
+	Base        string        `json:"baseAddress" tabs:"Base address"`
+	Requested   *int          `json:"requestedHosts,omitempty" tabs:"Requested"`
+	UsableHosts *big.Int      `json:"usableHosts" tabs:"Usable hosts"`
+	Wasted      *big.Int      `json:"wastedAddresses,omitempty" tabs:"Wasted"`
+	Usable      network.Range `json:"usable" tabs:"Usable range"`
+	Addresses   *big.Int      `json:"addresses" tabs:"Addresses"`
+}
+
+func newVLSMOutput(p netip.Prefix, status string) vlsmOutput {
+	s := newSubnetOutput("", p)
+	a := network.VLSMAllocation{Prefix: p}
+	return vlsmOutput{Subnet: s.Subnet, Status: status, Base: s.Base, UsableHosts: a.UsableHosts(), Usable: s.Usable, Addresses: s.Addresses}
+}
+
+// vlsmBlockOutput describes an allocated, reserved or leftover block of a plan by subnet count.
+type vlsmBlockOutput struct {
+	Name      string        `json:"name,omitempty" tabs:"Name"`
+	Block     string        `json:"block" tabs:"Block"`
+	Status    string        `json:"status" tabs:"Status"`
+	Requested string        `json:"requested,omitempty" tabs:"Requested"`
+	Slash64s  *big.Int      `json:"slash64s,omitempty" tabs:"/64s"`
+	Range     network.Range `json:"range" tabs:"Range"`
+	Subnets   []string      `json:"subnets,omitempty" tabs:"-"`
+}
+
+func newVLSMBlockOutput(p netip.Prefix, status string) vlsmBlockOutput {
+	o := vlsmBlockOutput{Block: p.String(), Status: status, Range: network.PrefixRange(p)}
+	if p.Addr().Is6() {
+		o.Slash64s = network.SubnetAllocation{Prefix: p}.Slash64s()
+	}
+	return o
+}
+
+// vlsmRequest is a request for hosts or, if subnets is set, for a number of subnets.
+type vlsmRequest struct {
+	hosts   network.VLSMRequest
+	subnets *network.SubnetRequest
 }
 
 var vlsmCmd = &cobra.Command{
-	Use:     "vlsm",
-	Short:   "VLSM takes a CIDR and divides it into smaller subnets based on the number of hosts required.",
+	Use:   "vlsm",
+	Short: "VLSM takes a CIDR and divides it into smaller subnets based on the number of hosts required.",
+	Long: `VLSM allocates a subnet for every host count, giving each the smallest free block that fits.
+Requests can be named as name=hosts, and every subnet is listed with its name, the requested hosts,
+the usable hosts and the addresses wasted by rounding up to a power of two.
+Subnets are placed largest first and listed by address, unless --input-order is given.
+Blocks given with --reserve are already in use and are left out of the allocation.
+
+For v6, where host counts are meaningless, request subnets instead as [name=][count x]/length,
+for example lan=12x/64 or wan=/56. Each request gets one block that holds all of its subnets,
+and every block is listed with the number of /64s it holds. With --nibble, blocks are rounded
+up to a 4-bit boundary, so they start and end on a hex digit and can be delegated in reverse DNS.`,
 	Aliases: []string{"v"},
-	Example: "cidr vlsm 10.0.0.0/16 120 60 30 10",
+	Example: `cidr vlsm 10.0.0.0/16 120 60 30 10
+cidr vlsm 10.0.0.0/24 web=120 db=30 mgmt=10
+cidr vlsm 10.0.0.0/22 web=120 --reserve 10.0.0.0/24 --input-order
+cidr vlsm 10.0.0.0/16 -f hosts.txt -o json
+cidr vlsm 2001:db8::/48 lan=12x/64 wan=3x/56 --nibble`,
+	Annotations: map[string]string{takesNetwork: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) < 2 {
-			cmd.PrintErrln("Usage: cidr vlsm <CIDR> <host count> <host count 2>...")
+		if len(args) < 1 {
+			cmd.PrintErrln("Usage: cidr vlsm <CIDR> [<name>=]<host count | [<count>x]/<length>> ...")
 			os.Exit(1)
 		}
 
-		n, err := network.New(args[0])
+		p, err := parsePrefix(args[0])
 		if err != nil {
 			cmd.PrintErrf("Error: %s\n", err)
 			os.Exit(1)
 		}
 
-		hostNum := args[1:]
-		integers := make([]int, len(hostNum))
-		for i, h := range hostNum {
-			var num int
-			if _, err := fmt.Sscanf(h, "%d", &num); err != nil {
-				cmd.PrintErrf("Invalid host count: %s\n", h)
-				os.Exit(1)
-			}
-			integers[i] = num
+		lines := readLines(cmd, args[1:])
+		if len(lines) < 1 {
+			cmd.PrintErrln("Usage: cidr vlsm <CIDR> [<name>=]<host count | [<count>x]/<length>> ...")
+			os.Exit(1)
+		}
+		// Every request must be valid, as a skipped one would change the allocation.
+		reqs, _, ok := parseLines(cmd, lines, parseVLSMRequest)
+		if !ok {
+			os.Exit(1)
 		}
 
-		allocated, leftover, err := n.VLSM(integers)
+		var reserved []netip.Prefix
+		for _, r := range vlsmReserve {
+			reserved = append(reserved, readSet(cmd, r)...)
+		}
+		plan, err := planVLSM(p, reqs, network.VLSMOptions{Reserved: reserved, InputOrder: vlsmInputOrder, Nibble: vlsmNibble})
 		if err != nil {
 			cmd.PrintErrf("Error: %s\n", err)
 			os.Exit(1)
 		}
 
-		if len(allocated) == 0 {
-			cmd.PrintErrln("No subnets allocated. Please check the host counts and CIDR.")
-			os.Exit(1)
+		if f, ok := outputFormatter(cmd); ok {
+			printResult(cmd, f, plan.rows())
+			return
+		}
+		plan.print(cmd)
+	},
+}
+
+// vlsmPlan is a plan by host count or, if blocks is set, by subnet count.
+type vlsmPlan struct {
+	hosts    []network.VLSMAllocation
+	blocks   []network.SubnetAllocation
+	reserved []netip.Prefix
+	leftover []netip.Prefix
+}
+
+// planVLSM allocates the requests in p. Requests by host count and by subnet count cannot be mixed.
+func planVLSM(p netip.Prefix, reqs []vlsmRequest, opts network.VLSMOptions) (vlsmPlan, error) {
+	var hosts []network.VLSMRequest
+	var subnets []network.SubnetRequest
+	for _, r := range reqs {
+		if r.subnets != nil {
+			subnets = append(subnets, *r.subnets)
+		} else {
+			hosts = append(hosts, r.hosts)
 		}
+	}
 
-		if len(allocated) > 0 {
-			cmd.Println("Allocated subnets:")
-			for _, a := range allocated {
-				cmd.Printf("  %s\n", a)
+	var plan vlsmPlan
+	var err error
+	switch {
+	case len(subnets) > 0 && len(hosts) > 0:
+		return vlsmPlan{}, fmt.Errorf("cannot mix host counts and subnet requests")
+	case len(subnets) > 0:
+		plan.blocks, plan.leftover, err = network.VLSMSubnets(p, subnets, opts)
+	default:
+		plan.hosts, plan.leftover, err = network.VLSM(p, hosts, opts)
+	}
+	if err != nil {
+		return vlsmPlan{}, err
+	}
+	plan.reserved = network.Intersect([]netip.Prefix{p.Masked()}, opts.Reserved)
+	return plan, nil
+}
+
+// rows returns the plan as []vlsmOutput, or as []vlsmBlockOutput for a plan by subnet count.
+func (v vlsmPlan) rows() any {
+	if v.blocks != nil {
+		o := make([]vlsmBlockOutput, 0, len(v.blocks)+len(v.reserved)+len(v.leftover))
+		for _, a := range v.blocks {
+			row := newVLSMBlockOutput(a.Prefix, "allocated")
+			row.Name, row.Requested = a.Name, a.SubnetRequest.String()
+			for _, s := range a.Subnets() {
+				row.Subnets = append(row.Subnets, s.String())
 			}
+			o = append(o, row)
+		}
+		for _, r := range v.reserved {
+			o = append(o, newVLSMBlockOutput(r, "reserved"))
+		}
+		for _, l := range v.leftover {
+			o = append(o, newVLSMBlockOutput(l, "leftover"))
 		}
+		return o
+	}
 
-		if len(leftover) > 0 {
-			cmd.Printf("Leftover subnets:\n")
-			for _, l := range leftover {
-				cmd.Printf("  %s\n", l)
+	o := make([]vlsmOutput, 0, len(v.hosts)+len(v.reserved)+len(v.leftover))
+	for _, a := range v.hosts {
+		row := newVLSMOutput(a.Prefix, "allocated")
+		row.Name, row.Requested, row.Wasted = a.Name, &a.Hosts, a.Wasted()
+		o = append(o, row)
+	}
+	for _, r := range v.reserved {
+		o = append(o, newVLSMOutput(r, "reserved"))
+	}
+	for _, l := range v.leftover {
+		o = append(o, newVLSMOutput(l, "leftover"))
+	}
+	return o
+}
+
+// print writes the plan as plain text.
+func (v vlsmPlan) print(cmd *cobra.Command) {
+	if v.blocks != nil {
+		cmd.Println("Allocated blocks:")
+		for _, a := range v.blocks {
+			slash64s := ""
+			if a.Prefix.Addr().Is6() {
+				slash64s = fmt.Sprintf(", %s /64s", a.Slash64s())
+			}
+			cmd.Printf("  %s%s: %s%s\n", a.Prefix, vlsmName(a.Name), a.SubnetRequest, slash64s)
+			// A block that is exactly the one requested subnet needs no breakdown.
+			if subnets := a.Subnets(); len(subnets) > 1 || subnets[0] != a.Prefix {
+				for _, s := range subnets {
+					cmd.Printf("    %s\n", s)
+				}
 			}
 		}
-	},
+	} else {
+		cmd.Println("Allocated subnets:")
+		for _, a := range v.hosts {
+			cmd.Printf("  %s%s: %d requested, %s usable, %s wasted\n", a.Prefix, vlsmName(a.Name), a.Hosts, a.UsableHosts(), a.Wasted())
+		}
+	}
+
+	if len(v.reserved) > 0 {
+		cmd.Printf("Reserved subnets:\n")
+		for _, r := range v.reserved {
+			cmd.Printf("  %s\n", r)
+		}
+	}
+
+	if len(v.leftover) > 0 {
+		cmd.Printf("Leftover subnets:\n")
+		for _, l := range v.leftover {
+			cmd.Printf("  %s\n", l)
+		}
+	}
+}
+
+// vlsmName returns the name of a request to print after its prefix.
+func vlsmName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return " " + name
+}
+
+// parseVLSMRequest parses a request written as "hosts" or "[count x]/length",
+// optionally preceded by "name=".
+func parseVLSMRequest(s string) (vlsmRequest, error) {
+	name, count, named := strings.Cut(s, "=")
+	if !named {
+		count, name = name, ""
+	}
+	name = strings.TrimSpace(name)
+	if named && name == "" {
+		return vlsmRequest{}, fmt.Errorf("missing name before '=': %s", s)
+	}
+	count = strings.TrimSpace(count)
+
+	if strings.Contains(count, "/") {
+		r, err := parseSubnetRequest(count)
+		if err != nil {
+			return vlsmRequest{}, err
+		}
+		r.Name = name
+		return vlsmRequest{subnets: &r}, nil
+	}
+	hosts, err := parseHostCount(count)
+	if err != nil {
+		return vlsmRequest{}, err
+	}
+	return vlsmRequest{hosts: network.VLSMRequest{Name: name, Hosts: hosts}}, nil
+}
+
+// parseSubnetRequest parses "12x/64", or "/56" for a single subnet.
+func parseSubnetRequest(s string) (network.SubnetRequest, error) {
+	count, length, _ := strings.Cut(s, "/")
+	r := network.SubnetRequest{Count: 1}
+	if count = strings.TrimSpace(count); count != "" {
+		n, ok := strings.CutSuffix(strings.ToLower(count), "x")
+		c, err := strconv.Atoi(strings.TrimSpace(n))
+		if !ok || err != nil {
+			return network.SubnetRequest{}, fmt.Errorf("invalid subnet request, want [<count>x]/<length>: %s", s)
+		}
+		r.Count = c
+	}
+	bits, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		return network.SubnetRequest{}, fmt.Errorf("invalid prefix length: %s", s)
+	}
+	r.Bits = bits
+	return r, nil
+}
+
+func parseHostCount(s string) (int, error) {
+	num, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid host count: %s", s)
+	}
+	return num, nil
 }
@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+// Host counts are parsed whole, so "10abc" and "10.5" are errors rather than 10.
+func TestParseVLSMRequest(t *testing.T) {
+	tests := []struct {
+		in    string
+		hosts int // -1 for an error
+	}{
+		{"web=10", 10},
+		{"web= 10 ", 10},
+		{"120", 120},
+		{"web=10abc", -1},
+		{"web=10.5", -1},
+		{"web=", -1},
+	}
+	for _, tt := range tests {
+		r, err := parseVLSMRequest(tt.in)
+		if tt.hosts < 0 {
+			if err == nil {
+				t.Errorf(`parseVLSMRequest(%q) = %+v, want an error`, tt.in, r.hosts)
+			}
+			continue
+		}
+		if err != nil || r.hosts.Hosts != tt.hosts {
+			t.Errorf(`parseVLSMRequest(%q) = %+v, %v, want %d hosts`, tt.in, r.hosts, err, tt.hosts)
+		}
+	}
+}
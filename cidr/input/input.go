@@ -0,0 +1,119 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/output"
+)
+
+// Line is a single entry read from the command line, a file or stdin.
+type Line struct {
+	Source string // "args", "stdin" or the file name
+	Number int    // 1-based position within the source
+	Text   string // trimmed content without comments
+}
+
+// String returns the origin of the line as "source:number".
+func (l Line) String() string {
+	return fmt.Sprintf("%s:%d", l.Source, l.Number)
+}
+
+// Read collects entries from args and files, in that order.
+// An argument of "-" reads from stdin, as does passing neither args nor files
+// unless stdin is a terminal. Blank lines and everything following a '#' are skipped.
+func Read(args, files []string, stdin io.Reader) ([]Line, error) {
+	var lines []Line
+	for l, err := range Scan(args, files, stdin) {
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+// Scan is like Read, but yields entries one at a time as they are read,
+// so large inputs can be processed without holding them in memory.
+// Iteration stops after the first error.
+func Scan(args, files []string, stdin io.Reader) iter.Seq2[Line, error] {
+	return func(yield func(Line, error) bool) {
+		if len(args) == 0 && len(files) == 0 {
+			if !isTerminal(stdin) {
+				scan("stdin", stdin, yield)
+			}
+			return
+		}
+
+		for i, arg := range args {
+			if arg == "-" {
+				if !scan("stdin", stdin, yield) {
+					return
+				}
+				continue
+			}
+			if text := clean(arg); text != "" {
+				if !yield(Line{Source: "args", Number: i + 1, Text: text}, nil) {
+					return
+				}
+			}
+		}
+
+		for _, name := range files {
+			if !scanFile(name, stdin, yield) {
+				return
+			}
+		}
+	}
+}
+
+func scanFile(name string, stdin io.Reader, yield func(Line, error) bool) bool {
+	if name == "-" {
+		return scan("stdin", stdin, yield)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		yield(Line{}, err)
+		return false
+	}
+	defer f.Close()
+	return scan(name, f, yield)
+}
+
+// scan yields the entries of r and reports whether iteration should continue.
+func scan(source string, r io.Reader, yield func(Line, error) bool) bool {
+	s := bufio.NewScanner(r)
+	n := 0
+	for s.Scan() {
+		n++
+		if text := clean(s.Text()); text != "" {
+			if !yield(Line{Source: source, Number: n, Text: text}, nil) {
+				return false
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		yield(Line{}, fmt.Errorf("reading %s: %w", source, err))
+		return false
+	}
+	return true
+}
+
+// clean strips comments and surrounding whitespace.
+func clean(s string) string {
+	if i := strings.IndexByte(s, '#'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSpace(s)
+}
+
+// isTerminal reports whether r is an interactive terminal,
+// in which case nothing is read unless asked for explicitly with "-".
+func isTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && output.IsTerminal(f)
+}
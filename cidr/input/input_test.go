@@ -0,0 +1,121 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFile creates a file with content in a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// Line numbers count the skipped lines too and restart for every source,
+// so an error points at the right line of the right file.
+func TestRead(t *testing.T) {
+	a := writeFile(t, "a.txt", "# header\n10.0.0.0/8\n\n  10.1.0.0/16  # inline\n")
+	b := writeFile(t, "b.txt", "\n192.168.0.0/24\n")
+	tests := []struct {
+		name  string
+		args  []string
+		files []string
+		stdin string
+		want  []Line
+	}{
+		{
+			name:  "stdin without args or files",
+			stdin: "# comment\n\n10.0.0.0/8\n   \n10.1.0.0/16 # trailing\n",
+			want:  []Line{{"stdin", 3, "10.0.0.0/8"}, {"stdin", 5, "10.1.0.0/16"}},
+		},
+		{
+			name: "args skip blanks and comments",
+			args: []string{"10.0.0.0/8", " ", "# only a comment", "10.1.0.0/16"},
+			want: []Line{{"args", 1, "10.0.0.0/8"}, {"args", 4, "10.1.0.0/16"}},
+		},
+		{
+			name:  "numbers restart in every file",
+			files: []string{a, b},
+			want:  []Line{{a, 2, "10.0.0.0/8"}, {a, 4, "10.1.0.0/16"}, {b, 2, "192.168.0.0/24"}},
+		},
+		{
+			name:  "dash argument reads stdin",
+			args:  []string{"172.16.0.0/12", "-"},
+			stdin: "10.0.0.0/8\n",
+			want:  []Line{{"args", 1, "172.16.0.0/12"}, {"stdin", 1, "10.0.0.0/8"}},
+		},
+		{
+			name:  "dash file reads stdin",
+			files: []string{"-"},
+			stdin: "\n10.0.0.0/8\n",
+			want:  []Line{{"stdin", 2, "10.0.0.0/8"}},
+		},
+		{
+			name:  "args come before files",
+			args:  []string{"172.16.0.0/12"},
+			files: []string{b},
+			stdin: "10.0.0.0/8\n",
+			want:  []Line{{"args", 1, "172.16.0.0/12"}, {b, 2, "192.168.0.0/24"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(tt.args, tt.files, strings.NewReader(tt.stdin))
+			if err != nil {
+				t.Fatalf(`Read(%q, %q) returned error: %s`, tt.args, tt.files, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf(`Read(%q, %q) = %v, want %v`, tt.args, tt.files, got, tt.want)
+			}
+		})
+	}
+}
+
+// A missing file fails the whole read rather than returning the lines read so far.
+func TestReadMissingFile(t *testing.T) {
+	// arrange
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+
+	// act
+	got, err := Read([]string{"10.0.0.0/8"}, []string{missing}, strings.NewReader(""))
+
+	// assert
+	if err == nil {
+		t.Errorf(`Read(%q) = %v, want an error`, missing, got)
+	}
+}
+
+// Breaking out of Scan must not go on to open the next source.
+func TestScanStops(t *testing.T) {
+	// arrange
+	missing := filepath.Join(t.TempDir(), "missing.txt")
+	var got []Line
+
+	// act
+	for l, err := range Scan([]string{"10.0.0.0/8", "10.1.0.0/16"}, []string{missing}, strings.NewReader("")) {
+		if err != nil {
+			t.Fatalf(`Scan returned error: %s`, err)
+		}
+		got = append(got, l)
+		break
+	}
+
+	// assert
+	if want := []Line{{"args", 1, "10.0.0.0/8"}}; !slices.Equal(got, want) {
+		t.Errorf(`Scan = %v, want %v`, got, want)
+	}
+}
+
+// Errors print a line as source:number.
+func TestLineString(t *testing.T) {
+	if got, want := (Line{Source: "a.txt", Number: 3, Text: "x"}).String(), "a.txt:3"; got != want {
+		t.Errorf(`Line.String() = %q, want %q`, got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package ipam
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+)
+
+// State is the complete set of pools and their allocations.
+type State struct {
+	Pools []*Pool `json:"pools"`
+}
+
+// Pool is a named network that blocks are allocated from.
+type Pool struct {
+	Name        string       `json:"name"`
+	Prefix      netip.Prefix `json:"prefix"`
+	Allocations []Allocation `json:"allocations"`
+}
+
+// Allocation is a block of a pool handed out to an owner.
+type Allocation struct {
+	Prefix  netip.Prefix `json:"prefix"`
+	Owner   string       `json:"owner"`
+	Created time.Time    `json:"created"`
+}
+
+// CreatePool adds a new pool. Pool names must be unique
+// and pools may not overlap, so an address has at most one owner.
+func (s *State) CreatePool(name string, prefix netip.Prefix) (*Pool, error) {
+	if name == "" {
+		return nil, fmt.Errorf("pool name must not be empty")
+	}
+	prefix = prefix.Masked()
+	for _, p := range s.Pools {
+		if p.Name == name {
+			return nil, fmt.Errorf("pool %q already exists", name)
+		}
+		if p.Prefix.Overlaps(prefix) {
+			return nil, fmt.Errorf("%s overlaps %s of pool %q", prefix, p.Prefix, p.Name)
+		}
+	}
+	p := &Pool{Name: name, Prefix: prefix, Allocations: []Allocation{}}
+	s.Pools = append(s.Pools, p)
+	return p, nil
+}
+
+// Pool returns the pool with the given name.
+func (s *State) Pool(name string) (*Pool, error) {
+	for _, p := range s.Pools {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("pool %q does not exist", name)
+}
+
+// DeletePool removes a pool. Pools with allocations cannot be deleted.
+func (s *State) DeletePool(name string) error {
+	for i, p := range s.Pools {
+		if p.Name != name {
+			continue
+		}
+		if len(p.Allocations) > 0 {
+			return fmt.Errorf("pool %q still has %d allocations", name, len(p.Allocations))
+		}
+		s.Pools = append(s.Pools[:i], s.Pools[i+1:]...)
+		return nil
+	}
+	return fmt.Errorf("pool %q does not exist", name)
+}
+
+// Free returns the unallocated space of the pool as the smallest list of prefixes.
+func (p *Pool) Free() []netip.Prefix {
+	used := make([]netip.Prefix, len(p.Allocations))
+	for i, a := range p.Allocations {
+		used[i] = a.Prefix
+	}
+	return network.Exclude([]netip.Prefix{p.Prefix}, used)
+}
+
+// Allocate hands out the next free, aligned block with the given prefix length.
+// The smallest free block that fits is split, so larger blocks stay available.
+func (p *Pool) Allocate(bits int, owner string, now time.Time) (Allocation, error) {
+	if bits < p.Prefix.Bits() {
+		return Allocation{}, fmt.Errorf("a /%d does not fit in pool %q (%s)", bits, p.Name, p.Prefix)
+	}
+	prefix, _, err := network.Allocate(p.Prefix, p.Free(), bits)
+	if err != nil {
+		return Allocation{}, fmt.Errorf("pool %q: %w", p.Name, err)
+	}
+
+	a := Allocation{Prefix: prefix, Owner: owner, Created: now.UTC()}
+	p.Allocations = append(p.Allocations, a)
+	slices.SortFunc(p.Allocations, func(a, b Allocation) int {
+		return a.Prefix.Addr().Compare(b.Prefix.Addr())
+	})
+	return a, nil
+}
+
+// Release returns an allocated block to the pool.
+func (p *Pool) Release(prefix netip.Prefix) (Allocation, error) {
+	prefix = prefix.Masked()
+	for i, a := range p.Allocations {
+		if a.Prefix == prefix {
+			p.Allocations = append(p.Allocations[:i], p.Allocations[i+1:]...)
+			return a, nil
+		}
+	}
+	return Allocation{}, fmt.Errorf("%s is not allocated in pool %q", prefix, p.Name)
+}
@@ -0,0 +1,88 @@
+package ipam
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+	"time"
+)
+
+// A /23 asked for between two /24s skips ahead to the next aligned /23, and
+// releasing the two /24s merges them back into one free /23.
+func TestPoolAllocate(t *testing.T) {
+	// arrange
+	var s State
+	p, err := s.CreatePool("corp", netip.MustParsePrefix("10.0.0.0/22"))
+	if err != nil {
+		t.Fatalf(`CreatePool() returned error: %s`, err)
+	}
+
+	// act
+	var got []netip.Prefix
+	for _, bits := range []int{24, 23, 24} {
+		a, err := p.Allocate(bits, "team", time.Now())
+		if err != nil {
+			t.Fatalf(`Allocate(%d) returned error: %s`, bits, err)
+		}
+		got = append(got, a.Prefix)
+	}
+
+	// assert
+	want := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/24"),
+		netip.MustParsePrefix("10.0.2.0/23"),
+		netip.MustParsePrefix("10.0.1.0/24"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf(`Allocate() = %v, want match for %v`, got, want)
+	}
+	if _, err := p.Allocate(24, "team", time.Now()); err == nil {
+		t.Errorf(`Allocate() on a full pool = nil error, want error`)
+	}
+
+	for _, r := range []string{"10.0.0.0/24", "10.0.1.0/24"} {
+		if _, err := p.Release(netip.MustParsePrefix(r)); err != nil {
+			t.Fatalf(`Release(%s) returned error: %s`, r, err)
+		}
+	}
+	if free, want := p.Free(), []netip.Prefix{netip.MustParsePrefix("10.0.0.0/23")}; !slices.Equal(free, want) {
+		t.Errorf(`Free() = %v, want match for %v`, free, want)
+	}
+}
+
+// A full v6 pool reports that no /64 is free, rather than failing
+// on a prefix length worked out for a v4 address.
+func TestPoolAllocateFullV6(t *testing.T) {
+	// arrange
+	var s State
+	p, err := s.CreatePool("v6", netip.MustParsePrefix("2001:db8::/64"))
+	if err != nil {
+		t.Fatalf(`CreatePool() returned error: %s`, err)
+	}
+	if _, err := p.Allocate(64, "team", time.Now()); err != nil {
+		t.Fatalf(`Allocate(64) returned error: %s`, err)
+	}
+
+	// act
+	_, err = p.Allocate(64, "team", time.Now())
+
+	// assert
+	if want := `pool "v6": no free block of /64`; err == nil || err.Error() != want {
+		t.Errorf(`Allocate(64) on a full pool = %v, want %q`, err, want)
+	}
+}
+
+// Pools are keyed by name and may not share addresses,
+// so both a second pool of the same name and an overlapping one are refused.
+func TestCreatePool(t *testing.T) {
+	var s State
+	if _, err := s.CreatePool("corp", netip.MustParsePrefix("10.0.0.0/8")); err != nil {
+		t.Fatalf(`CreatePool() returned error: %s`, err)
+	}
+	if _, err := s.CreatePool("corp", netip.MustParsePrefix("192.168.0.0/16")); err == nil {
+		t.Errorf(`CreatePool() with a duplicate name = nil error, want error`)
+	}
+	if _, err := s.CreatePool("lab", netip.MustParsePrefix("10.1.0.0/16")); err == nil {
+		t.Errorf(`CreatePool() with an overlapping prefix = nil error, want error`)
+	}
+}
@@ -0,0 +1,115 @@
+package ipam
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockTimeout  = 10 * time.Second
+	lockInterval = 50 * time.Millisecond
+)
+
+// Store persists State as JSON in a local file.
+// Changes go through Update, which holds an exclusive lock on the file,
+// so two concurrent allocations can never hand out the same block.
+type Store struct {
+	path string
+}
+
+// NewStore creates a Store backed by the file at path.
+// The file and its directory are created on first update.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// DefaultPath returns the state file location, which is $CIDR_IPAM_STATE
+// if set, or ipam.json in the user configuration directory.
+func DefaultPath() string {
+	if p := os.Getenv("CIDR_IPAM_STATE"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "ipam.json"
+	}
+	return filepath.Join(dir, "cidr", "ipam.json")
+}
+
+// Load reads the current state. A missing file is an empty state.
+func (s *Store) Load() (*State, error) {
+	b, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &State{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, fmt.Errorf("invalid state file %s: %w", s.path, err)
+	}
+	return &st, nil
+}
+
+// Update locks the state file, loads the state and calls fn with it.
+// The state is saved only if fn returns nil.
+func (s *Store) Update(fn func(*State) error) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	st, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(st); err != nil {
+		return err
+	}
+	return s.save(st)
+}
+
+// save writes the state to a temporary file and renames it over the
+// state file, so readers never observe a partially written file.
+func (s *Store) save(st *State) error {
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// lock acquires the lock by exclusively creating a lock file next to the state file.
+// Creating a file with O_EXCL is atomic on every platform, so no build tags are needed.
+func (s *Store) lock() (func(), error) {
+	path := s.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, _ = fmt.Fprintf(f, "%d\n", os.Getpid())
+			_ = f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("state file is locked by another process; remove %s if it is stale", path)
+		}
+		time.Sleep(lockInterval)
+	}
+}
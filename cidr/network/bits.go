@@ -0,0 +1,137 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/jokarl/go-learning-projects/cidr/math"
+)
+
+// BitKind is the part of an address a bit belongs to.
+type BitKind int
+
+const (
+	NetworkBit BitKind = iota
+	SubnetBit
+	HostBit
+)
+
+// BitLayout splits the bits of an address into network bits, subnet bits
+// borrowed from the host bits by dividing the network, and host bits.
+type BitLayout struct {
+	Network  int
+	Borrowed int
+}
+
+// Kind returns the part bit i belongs to, counting from the most significant bit.
+func (l BitLayout) Kind(i int) BitKind {
+	switch {
+	case i < l.Network:
+		return NetworkBit
+	case i < l.Network+l.Borrowed:
+		return SubnetBit
+	default:
+		return HostBit
+	}
+}
+
+// BitRow is an address printed by explain --bits.
+type BitRow struct {
+	Label string
+	Addr  netip.Addr
+}
+
+// BitRows returns the address p was written with, its base address, its netmask,
+// and its broadcast address for v4 or its last address for v6.
+func BitRows(p netip.Prefix) []BitRow {
+	r := PrefixRange(p)
+	mask := make([]byte, p.Addr().BitLen()/8)
+	for i := 0; i < p.Bits(); i++ {
+		mask[i/8] |= 0x80 >> uint(i%8)
+	}
+	netmask, _ := netip.AddrFromSlice(mask)
+
+	last := "Last address"
+	if p.Addr().Is4() {
+		last = "Broadcast"
+	}
+	return []BitRow{
+		{Label: "Address", Addr: p.Addr().WithZone("")},
+		{Label: "Network", Addr: r.First},
+		{Label: "Netmask", Addr: netmask},
+		{Label: last, Addr: r.Last},
+	}
+}
+
+// DivideLayout returns the layout of p divided into count subnets,
+// borrowing as many bits as a divide into count subnets does.
+func DivideLayout(p netip.Prefix, count int) (BitLayout, error) {
+	if count < 1 {
+		return BitLayout{}, fmt.Errorf("subnet count must be > 0")
+	}
+	if host := p.Addr().BitLen() - p.Bits(); host < 63 && count > 1<<host {
+		return BitLayout{}, fmt.Errorf("%s is too small to divide into %d subnets", p, count)
+	}
+	return BitLayout{Network: p.Bits(), Borrowed: math.NextPow2(count)}, nil
+}
+
+// BitRun is a run of characters that all belong to the same part of an address.
+// Sep is written before the run and belongs to neither part, so that a run
+// never starts or ends with a group separator.
+type BitRun struct {
+	Sep  string
+	Kind BitKind
+	Text string
+}
+
+// FormatBits writes a in binary grouped by octet for v4, or in hex nibbles for v6,
+// split into runs by the part of the layout they belong to. A v6 nibble split
+// between two parts is written as its four bits in parentheses.
+func FormatBits(a netip.Addr, l BitLayout) []BitRun {
+	var w bitWriter
+	b := a.AsSlice()
+	bit := func(i int) byte { return b[i/8] >> uint(7-i%8) & 1 }
+
+	if a.Is4() {
+		for i := 0; i < 32; i++ {
+			if i > 0 && i%8 == 0 {
+				w.pending = "."
+			}
+			w.write(l.Kind(i), string('0'+bit(i)))
+		}
+		return w.runs
+	}
+
+	for n := 0; n < 32; n++ {
+		if n > 0 && n%4 == 0 {
+			w.pending = ":"
+		}
+		first, last := l.Kind(n*4), l.Kind(n*4+3)
+		if first == last {
+			w.write(first, fmt.Sprintf("%x", b[n/2]>>uint(4*(1-n%2))&0xf))
+			continue
+		}
+		w.write(first, "(")
+		for i := n * 4; i < n*4+4; i++ {
+			w.write(l.Kind(i), string('0'+bit(i)))
+		}
+		w.write(last, ")")
+	}
+	return w.runs
+}
+
+// bitWriter collects runs of bits, starting a new run where the part they belong to changes.
+// A pending separator is written before the next bit, inside the run if the part does not change.
+type bitWriter struct {
+	runs    []BitRun
+	pending string
+}
+
+func (w *bitWriter) write(k BitKind, s string) {
+	if n := len(w.runs); n > 0 && w.runs[n-1].Kind == k {
+		w.runs[n-1].Text += w.pending + s
+	} else {
+		w.runs = append(w.runs, BitRun{Sep: w.pending, Kind: k, Text: s})
+	}
+	w.pending = ""
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"math"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// Runs change where the network, borrowed and host parts meet, even in the middle
+// of an octet or hextet.
+func TestFormatBits(t *testing.T) {
+	tests := []struct {
+		addr   string
+		layout BitLayout
+		want   []BitRun
+	}{
+		{"10.1.2.3", BitLayout{Network: 22, Borrowed: 2}, []BitRun{
+			{"", NetworkBit, "00001010.00000001.000000"}, {"", SubnetBit, "10"}, {".", HostBit, "00000011"},
+		}},
+		{"10.1.2.3", BitLayout{Network: 24, Borrowed: 8}, []BitRun{
+			{"", NetworkBit, "00001010.00000001.00000010"}, {".", SubnetBit, "00000011"},
+		}},
+		{"0.0.0.0", BitLayout{}, []BitRun{{"", HostBit, "00000000.00000000.00000000.00000000"}}},
+		{"2001:db8:abcd::", BitLayout{Network: 46, Borrowed: 2}, []BitRun{
+			{"", NetworkBit, "2001:0db8:abc(11"}, {"", SubnetBit, "01)"}, {":", HostBit, "0000:0000:0000:0000:0000"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			got := FormatBits(netip.MustParseAddr(tt.addr), tt.layout)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf(`FormatBits(%s, %+v) = %q, want %q`, tt.addr, tt.layout, got, tt.want)
+			}
+		})
+	}
+}
+
+// Five subnets borrow three bits. MaxInt subnets cannot fit a v4 /8 and must be
+// refused without looping, while a v6 /32 has room for the 63 bits they need.
+func TestDivideLayout(t *testing.T) {
+	// act
+	got, err := DivideLayout(netip.MustParsePrefix("10.0.0.0/22"), 5)
+
+	// assert
+	if err != nil {
+		t.Fatalf(`DivideLayout returned error: %s`, err)
+	}
+	if want := (BitLayout{Network: 22, Borrowed: 3}); got != want {
+		t.Errorf(`DivideLayout = %+v, want %+v`, got, want)
+	}
+	if _, err := DivideLayout(netip.MustParsePrefix("10.0.0.0/31"), 4); err == nil {
+		t.Errorf(`DivideLayout(10.0.0.0/31, 4) returned no error`)
+	}
+	if _, err := DivideLayout(netip.MustParsePrefix("10.0.0.0/8"), math.MaxInt); err == nil {
+		t.Errorf(`DivideLayout(10.0.0.0/8, MaxInt) returned no error`)
+	}
+	if got, err := DivideLayout(netip.MustParsePrefix("2001:db8::/32"), math.MaxInt); err != nil || got.Borrowed != 63 {
+		t.Errorf(`DivideLayout(2001:db8::/32, MaxInt) = %+v, %v, want 63 borrowed bits`, got, err)
+	}
+}
+
+// For a v4 prefix, explain --bits shows the address, network, netmask and broadcast.
+func TestBitRows(t *testing.T) {
+	// act
+	rows := BitRows(netip.MustParsePrefix("10.1.2.3/22"))
+
+	// assert
+	want := []BitRow{
+		{"Address", netip.MustParseAddr("10.1.2.3")},
+		{"Network", netip.MustParseAddr("10.1.0.0")},
+		{"Netmask", netip.MustParseAddr("255.255.252.0")},
+		{"Broadcast", netip.MustParseAddr("10.1.3.255")},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf(`BitRows returned %d rows, want %d`, len(rows), len(want))
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf(`BitRows[%d] = %+v, want %+v`, i, rows[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package network
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// The functions in this file build and decode the interface identifier (IID),
+// the low 64 bits of a v6 address that SLAAC derives from the interface.
+
+// EUI64 returns the address in p whose IID is the modified EUI-64 of mac (RFC 4291 appendix A):
+// ff:fe is inserted in the middle of a 48-bit MAC and the universal/local bit is flipped.
+// 64-bit EUIs are used as they are, apart from the flipped bit.
+func EUI64(p netip.Prefix, mac net.HardwareAddr) (netip.Addr, error) {
+	if err := checkIIDPrefix(p); err != nil {
+		return netip.Addr{}, err
+	}
+	var iid [8]byte
+	switch len(mac) {
+	case 6:
+		copy(iid[:3], mac[:3])
+		iid[3], iid[4] = 0xff, 0xfe
+		copy(iid[5:], mac[3:])
+	case 8:
+		copy(iid[:], mac)
+	default:
+		return netip.Addr{}, fmt.Errorf("MAC must be 48 or 64 bits, got %d", len(mac)*8)
+	}
+	iid[0] ^= 0x02
+	return withIID(p, iid), nil
+}
+
+// MAC returns the 48-bit MAC address an EUI-64 based address was derived from.
+// It returns an error if the IID does not contain ff:fe in the middle.
+func MAC(a netip.Addr) (net.HardwareAddr, error) {
+	if !a.Is6() || a.Is4In6() {
+		return nil, fmt.Errorf("not an IPv6 address: %s", a)
+	}
+	b := a.As16()
+	if b[11] != 0xff || b[12] != 0xfe {
+		return nil, fmt.Errorf("interface identifier of %s is not derived from a MAC address", a)
+	}
+	mac := net.HardwareAddr{b[8] ^ 0x02, b[9], b[10], b[13], b[14], b[15]}
+	return mac, nil
+}
+
+// StableIIDOptions are the inputs of an RFC 7217 interface identifier besides the prefix.
+type StableIIDOptions struct {
+	// Interface is the name of the interface, e.g. "eth0".
+	Interface string
+	// NetworkID optionally identifies the network, e.g. a Wi-Fi SSID.
+	NetworkID string
+	// DADCounter is the number of duplicate address detection failures so far.
+	DADCounter int
+	// Secret is the key that keeps the IID from being predicted by others.
+	Secret []byte
+}
+
+// StableIID returns the address in p with a stable, semantically opaque IID (RFC 7217).
+// The IID is the low 64 bits of SHA-256 over the /64 prefix, the interface name, the network ID,
+// the DAD counter as a big-endian uint32 and the secret, in that order. The RFC leaves the
+// function to the implementation, so the result matches this tool rather than a given OS.
+// If the IID is reserved (RFC 5453), the counter is incremented and the IID computed again;
+// the counter used is returned with the address.
+func StableIID(p netip.Prefix, opts StableIIDOptions) (netip.Addr, int, error) {
+	if err := checkIIDPrefix(p); err != nil {
+		return netip.Addr{}, 0, err
+	}
+	if len(opts.Secret) == 0 {
+		return netip.Addr{}, 0, fmt.Errorf("secret key must not be empty")
+	}
+	if opts.DADCounter < 0 {
+		return netip.Addr{}, 0, fmt.Errorf("DAD counter must be >= 0 (got %d)", opts.DADCounter)
+	}
+
+	prefix := p.Masked().Addr().As16()
+	for counter := opts.DADCounter; ; counter++ {
+		h := sha256.New()
+		h.Write(prefix[:8])
+		h.Write([]byte(opts.Interface))
+		h.Write([]byte(opts.NetworkID))
+		binary.Write(h, binary.BigEndian, uint32(counter))
+		h.Write(opts.Secret)
+		sum := h.Sum(nil)
+
+		iid := [8]byte(sum[len(sum)-8:])
+		if !reservedIID(iid) {
+			return withIID(p, iid), counter, nil
+		}
+	}
+}
+
+// reservedIID reports whether iid is in the IANA reserved IPv6 interface identifiers (RFC 5453).
+func reservedIID(iid [8]byte) bool {
+	switch {
+	case iid == [8]byte{}: // subnet-router anycast
+		return true
+	case bytes.Equal(iid[:7], []byte{0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}) && iid[7] >= 0x80: // subnet anycast
+		return true
+	case bytes.Equal(iid[:5], []byte{0x02, 0x00, 0x5e, 0xff, 0xfe}): // proxy mobile
+		return true
+	}
+	return false
+}
+
+// checkIIDPrefix checks that p is a v6 prefix that leaves room for a 64-bit IID.
+func checkIIDPrefix(p netip.Prefix) error {
+	if !p.Addr().Is6() || p.Addr().Is4In6() {
+		return fmt.Errorf("interface identifiers need an IPv6 prefix, got %s", p)
+	}
+	if p.Bits() > 64 {
+		return fmt.Errorf("interface identifiers need a /64 or shorter prefix, got /%d", p.Bits())
+	}
+	return nil
+}
+
+// withIID returns the address formed by the first 64 bits of p and iid.
+func withIID(p netip.Prefix, iid [8]byte) netip.Addr {
+	b := p.Masked().Addr().As16()
+	copy(b[8:], iid[:])
+	return netip.AddrFrom16(b)
+}
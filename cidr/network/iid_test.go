@@ -0,0 +1,100 @@
+package network
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+)
+
+// A 48-bit MAC gets ff:fe inserted and the universal/local bit flipped, a 64-bit one
+// only the bit flipped, and MAC must undo both to give the original back.
+func TestEUI64(t *testing.T) {
+	tests := []struct {
+		prefix string
+		mac    string
+		want   string
+	}{
+		{"2001:db8::/64", "00:11:22:33:44:55", "2001:db8::211:22ff:fe33:4455"},
+		{"fe80::/10", "02:00:5e:10:00:01", "fe80::5eff:fe10:1"},
+		{"2001:db8:1:2::/64", "00:11:22:33:44:55:66:77", "2001:db8:1:2:211:2233:4455:6677"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mac, func(t *testing.T) {
+			mac, err := net.ParseMAC(tt.mac)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := EUI64(netip.MustParsePrefix(tt.prefix), mac)
+			if err != nil {
+				t.Fatalf(`EUI64 returned error: %s`, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(`EUI64(%s, %s) = %s, want %s`, tt.prefix, tt.mac, got, tt.want)
+			}
+			if len(mac) == 6 {
+				if back, err := MAC(got); err != nil || back.String() != tt.mac {
+					t.Errorf(`MAC(%s) = %s, %v, want %s`, got, back, err, tt.mac)
+				}
+			}
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		mac, _ := net.ParseMAC("00:11:22:33:44:55")
+		for _, p := range []string{"2001:db8::/96", "10.0.0.0/8"} {
+			if _, err := EUI64(netip.MustParsePrefix(p), mac); err == nil {
+				t.Errorf(`EUI64(%s) returned no error`, p)
+			}
+		}
+		if _, err := MAC(netip.MustParseAddr("2001:db8::1")); err == nil {
+			t.Errorf(`MAC returned no error for an IID without ff:fe`)
+		}
+	})
+}
+
+// RFC 7217 addresses ignore the host bits of the prefix they are given but change
+// with the interface, and cannot be generated without a secret.
+func TestStableIID(t *testing.T) {
+	// arrange
+	p := netip.MustParsePrefix("2001:db8::/64")
+	opts := StableIIDOptions{Interface: "eth0", Secret: []byte("lab")}
+
+	// act
+	a, counter, err := StableIID(p, opts)
+	again, _, _ := StableIID(netip.MustParsePrefix("2001:db8::1234/64"), opts)
+	opts.Interface = "eth1"
+	other, _, _ := StableIID(p, opts)
+
+	// assert
+	if err != nil {
+		t.Fatalf(`StableIID returned error: %s`, err)
+	}
+	if a.String() != "2001:db8::396d:cc8f:ad9c:88ec" || counter != 0 {
+		t.Errorf(`StableIID = %s, %d, want 2001:db8::396d:cc8f:ad9c:88ec, 0`, a, counter)
+	}
+	if again != a {
+		t.Errorf(`StableIID depends on host bits: %s != %s`, again, a)
+	}
+	if other == a || !p.Contains(other) {
+		t.Errorf(`StableIID for another interface = %s, want a different address in %s`, other, p)
+	}
+	if _, _, err := StableIID(p, StableIIDOptions{Interface: "eth0"}); err == nil {
+		t.Errorf(`StableIID returned no error without a secret`)
+	}
+}
+
+// The subnet-router anycast IID, the RFC 2526 anycast range and the Proxy Mobile IPv6 IID
+// are skipped; the IIDs just outside them are not.
+func TestReservedIID(t *testing.T) {
+	for iid, want := range map[[8]byte]bool{
+		{}: true,
+		{0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x80}: true,
+		{0xfd, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}: false,
+		{0x02, 0x00, 0x5e, 0xff, 0xfe, 0x00, 0x52, 0x13}: true,
+		{0x02, 0x11, 0x22, 0xff, 0xfe, 0x33, 0x44, 0x55}: false,
+	} {
+		if got := reservedIID(iid); got != want {
+			t.Errorf(`reservedIID(%x) = %t, want %t`, iid, got, want)
+		}
+	}
+}
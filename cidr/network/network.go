@@ -2,8 +2,6 @@ package network
 
 import (
 	"fmt"
-	"net/netip"
-	"strings"
 
 	"github.com/jokarl/go-learning-projects/cidr/network/types"
 	"github.com/jokarl/go-learning-projects/cidr/network/v4"
@@ -11,18 +9,18 @@ import (
 )
 
 // New creates a new types.Network instance from a string representation.
-// It will automatically determine if it is a v4 or v6 network based on the input format.
+// It will automatically determine if it is a v4 or v6 network based on the input format,
+// and accepts every notation ParsePrefix does.
 func New(cidr string) (types.Network, error) {
-	cidr = strings.TrimSpace(cidr)
-	p, err := netip.ParsePrefix(cidr)
+	p, err := ParsePrefix(cidr)
 	if err != nil {
 		return nil, err
 	}
 	if p.Addr().Is4() {
-		return v4.NewNetwork(cidr)
+		return v4.NewNetwork(p.String())
 	}
 	if p.Addr().Is6() {
-		return v6.NewNetwork(cidr)
+		return v6.NewNetwork(p.String())
 	}
 	return nil, fmt.Errorf("unsupported address type: %s", cidr)
 }
@@ -1,18 +1,25 @@
 package network
 
 import (
+	"fmt"
 	"math/big"
+	"net/netip"
+	"strings"
 
 	"github.com/jokarl/go-learning-projects/cidr/network/types"
 	"github.com/jokarl/go-learning-projects/cidr/output"
+	"github.com/jokarl/go-learning-projects/cidr/registry"
 )
 
-type outputFormat struct {
+// Explanation describes a network, as printed by explain.
+type Explanation struct {
 	BaseAddress      string            `json:"baseAddress" tabs:"Base address"`
 	BroadcastAddress *string           `json:"broadcastAddress,omitempty" tabs:"Broadcast address,omitempty"`
 	Netmask          string            `json:"netmask" tabs:"Netmask"`
 	UsableAddresses  usableRangeOutput `json:"usableAddresses" tabs:"Usable addresses"`
 	TotalAddresses   *big.Int          `json:"totalAddresses" tabs:"Total addresses"`
+	Category         categoryOutput    `json:"category" tabs:"Category"`
+	Transition       *Transition       `json:"transition,omitempty" tabs:"Transition,omitempty"`
 }
 
 type usableRangeOutput struct {
@@ -24,9 +31,63 @@ func (u usableRangeOutput) String() string {
 	return u.First + " - " + u.Last
 }
 
-// PrintNetwork formats network information using the provided formatter
-func PrintNetwork(n types.Network, f output.Formatter) error {
-	o := outputFormat{
+// categoryOutput describes where the network sits in the IANA special-purpose registries.
+// The flags are left out when only parts of the network are special-purpose,
+// since they differ between the parts.
+type categoryOutput struct {
+	Category          registry.Category `json:"category"`
+	Name              string            `json:"name,omitempty"`
+	Block             *netip.Prefix     `json:"block,omitempty"`
+	RFC               string            `json:"rfc,omitempty"`
+	Forwardable       *bool             `json:"forwardable,omitempty"`
+	GloballyReachable *bool             `json:"globallyReachable,omitempty"`
+	Partial           []registry.Entry  `json:"partial,omitempty"`
+}
+
+func newCategoryOutput(p netip.Prefix) categoryOutput {
+	c := registry.Classify(p)
+	o := categoryOutput{Category: c.Category(), Partial: c.Partial}
+	if e, ok := c.Entry(); ok {
+		o.Name, o.Block, o.RFC = e.Name, &e.Prefix, e.RFC
+		o.Forwardable, o.GloballyReachable = &e.Forwardable, &e.GloballyReachable
+	} else if o.Category == registry.Global {
+		yes := true
+		o.Forwardable, o.GloballyReachable = &yes, &yes
+	}
+	return o
+}
+
+func (c categoryOutput) String() string {
+	var b strings.Builder
+	b.WriteString(string(c.Category))
+	if c.Block != nil {
+		fmt.Fprintf(&b, ": %s (%s, %s)", c.Name, c.Block, c.RFC)
+	}
+	if c.Forwardable != nil {
+		b.WriteString("; ")
+		if !*c.Forwardable {
+			b.WriteString("not ")
+		}
+		b.WriteString("forwardable, ")
+		if !*c.GloballyReachable {
+			b.WriteString("not ")
+		}
+		b.WriteString("globally reachable")
+	}
+	for i, e := range c.Partial {
+		if i == 0 {
+			b.WriteString("; partly ")
+		} else {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s %s (%s)", e.Category, e.Prefix, e.RFC)
+	}
+	return b.String()
+}
+
+// Explain collects the information printed for a network.
+func Explain(n types.Network) Explanation {
+	o := Explanation{
 		BaseAddress: n.BaseAddress().String(),
 		UsableAddresses: usableRangeOutput{
 			First: n.FirstUsableAddress().String(),
@@ -34,6 +95,10 @@ func PrintNetwork(n types.Network, f output.Formatter) error {
 		},
 		Netmask:        n.Netmask().String(),
 		TotalAddresses: n.Count(),
+		Category:       newCategoryOutput(n.Prefix()),
+	}
+	if t, ok := DecodeTransition(n.Prefix()); ok {
+		o.Transition = &t
 	}
 
 	// Only set broadcast address for IPv4 networks
@@ -41,6 +106,10 @@ func PrintNetwork(n types.Network, f output.Formatter) error {
 		broadcastStr := broadcastAddr.String()
 		o.BroadcastAddress = &broadcastStr
 	}
+	return o
+}
 
-	return f.Print(o)
+// PrintNetwork formats network information using the provided formatter
+func PrintNetwork(n types.Network, f output.Formatter) error {
+	return f.Print(Explain(n))
 }
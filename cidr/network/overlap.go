@@ -0,0 +1,60 @@
+package network
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// Relation describes how two overlapping prefixes relate to each other.
+type Relation string
+
+const (
+	// Equal means both prefixes cover the same addresses.
+	Equal Relation = "equal"
+	// Contains means the first prefix covers all addresses of the second.
+	Contains Relation = "contains"
+)
+
+// Overlap is a pair of overlapping prefixes, referenced by their index in the input.
+// A is always the larger (or equal) prefix, so the relation reads "A <relation> B".
+type Overlap struct {
+	A, B     int
+	Relation Relation
+	Shared   Range
+}
+
+// Overlaps returns every pair of prefixes in ps that share at least one address.
+// Two prefixes either nest or are disjoint, so the shared range is always the smaller prefix.
+func Overlaps(ps []netip.Prefix) []Overlap {
+	order := make([]int, len(ps))
+	for i := range order {
+		order[i] = i
+	}
+	// Sort by address, then larger prefixes first, so a prefix is
+	// always visited after every prefix that contains it.
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := ps[order[i]].Masked(), ps[order[j]].Masked()
+		if c := a.Addr().Compare(b.Addr()); c != 0 {
+			return c < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+
+	var out []Overlap
+	// open holds prefixes that contain each other, outermost first.
+	var open []int
+	for _, cur := range order {
+		for len(open) > 0 && !ps[open[len(open)-1]].Overlaps(ps[cur]) {
+			open = open[:len(open)-1]
+		}
+		for _, o := range open {
+			rel := Contains
+			if ps[o].Bits() == ps[cur].Bits() {
+				rel = Equal
+			}
+			out = append(out, Overlap{A: o, B: cur, Relation: rel, Shared: PrefixRange(ps[cur])})
+		}
+		open = append(open, cur)
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"testing"
+)
+
+// Prefixes nested three deep, a duplicate and both families, given unsorted: every
+// enclosing prefix must be paired with every prefix inside it, not only the closest one.
+func TestOverlaps(t *testing.T) {
+	// arrange
+	ps := prefixes(t,
+		"10.0.0.0/24",    // 0
+		"10.0.0.0/8",     // 1
+		"10.0.0.0/16",    // 2
+		"10.0.0.0/24",    // 3
+		"10.1.0.0/16",    // 4
+		"2001:db8::/32",  // 5
+		"2001:db8::/48",  // 6
+		"192.168.0.0/24", // 7
+		"10.0.1.0/24",    // 8
+	)
+	want := []Overlap{
+		{A: 1, B: 2, Relation: Contains},
+		{A: 1, B: 0, Relation: Contains},
+		{A: 2, B: 0, Relation: Contains},
+		{A: 1, B: 3, Relation: Contains},
+		{A: 2, B: 3, Relation: Contains},
+		{A: 0, B: 3, Relation: Equal},
+		{A: 1, B: 8, Relation: Contains},
+		{A: 2, B: 8, Relation: Contains},
+		{A: 1, B: 4, Relation: Contains},
+		{A: 5, B: 6, Relation: Contains},
+	}
+	for i := range want {
+		want[i].Shared = PrefixRange(ps[want[i].B])
+	}
+
+	// act
+	got := Overlaps(ps)
+
+	// assert
+	if len(got) != len(want) {
+		t.Fatalf(`Overlaps returned %d pairs, want %d: %+v`, len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf(`Overlaps[%d] = %+v, want %+v`, i, got[i], want[i])
+		}
+	}
+}
+
+// Touching prefixes do not overlap, and neither do 0.0.0.0/32 and ::/128,
+// which are address zero in different families.
+func TestOverlapsDisjoint(t *testing.T) {
+	// arrange
+	ps := prefixes(t, "10.0.1.0/24", "10.0.0.0/24", "10.0.2.0/23", "0.0.0.0/32", "::/128")
+
+	// act
+	got := Overlaps(ps)
+
+	// assert
+	if len(got) != 0 {
+		t.Errorf(`Overlaps(%v) = %+v, want none`, ps, got)
+	}
+}
@@ -0,0 +1,106 @@
+package network
+
+import (
+	"fmt"
+	"math/bits"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ParsePrefix parses a prefix written in one of these notations:
+//
+//	10.1.0.0/22                  CIDR
+//	10.1.0.0 255.255.252.0       dotted netmask, also as 10.1.0.0/255.255.252.0
+//	10.1.0.0 0.0.3.255           Cisco wildcard mask
+//	10.1.0.1, 2001:db8::1        bare address, a /32 or /128
+//	fe80::1%eth0/64              zone ID, which is dropped
+//	10.1.0.0-10.1.3.255          range, if it is exactly one prefix
+//
+// A mask of 0.0.0.0 is a wildcard matching a single host, unless the address is 0.0.0.0 too,
+// as in a default route. Surrounding whitespace is ignored; host bits are kept as given.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	ps, err := ParsePrefixes(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if len(ps) != 1 {
+		return netip.Prefix{}, fmt.Errorf("range %s is not a single CIDR, it needs %d", strings.TrimSpace(s), len(ps))
+	}
+	return ps[0], nil
+}
+
+// ParsePrefixes is like ParsePrefix, but also accepts ranges that need several prefixes.
+func ParsePrefixes(s string) ([]netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if p, err := netip.ParsePrefix(s); err == nil {
+		return []netip.Prefix{p}, nil
+	}
+
+	if strings.Contains(s, "-") && !strings.Contains(s, "%") {
+		r, err := ParseRange(s)
+		if err != nil {
+			return nil, err
+		}
+		return r.Prefixes(), nil
+	}
+
+	addr, mask, hasMask := strings.Cut(s, "/")
+	if !hasMask {
+		if f := strings.Fields(s); len(f) == 2 {
+			addr, mask, hasMask = f[0], f[1], true
+		}
+	}
+	a, err := netip.ParseAddr(strings.TrimSpace(addr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+	}
+	a = a.WithZone("")
+	if !hasMask {
+		return []netip.Prefix{netip.PrefixFrom(a, a.BitLen())}, nil
+	}
+
+	mask = strings.TrimSpace(mask)
+	n, err := strconv.Atoi(mask)
+	if err != nil {
+		if n, err = maskBits(a, mask); err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+		}
+	}
+	p := netip.PrefixFrom(a, n)
+	if !p.IsValid() {
+		return nil, fmt.Errorf("invalid CIDR %q: prefix length %d out of range", s, n)
+	}
+	return []netip.Prefix{p}, nil
+}
+
+// CheckHostBits returns an error if p has bits set after its prefix length.
+func CheckHostBits(p netip.Prefix) error {
+	if m := p.Masked(); m != p {
+		return fmt.Errorf("%s has host bits set, the network is %s", p, m)
+	}
+	return nil
+}
+
+// maskBits returns the prefix length of a dotted netmask or wildcard mask for a.
+func maskBits(a netip.Addr, mask string) (int, error) {
+	m, err := netip.ParseAddr(mask)
+	if err != nil || !m.Is4() || !a.Is4() {
+		return 0, fmt.Errorf("invalid prefix length or mask %q", mask)
+	}
+	b := m.As4()
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+
+	isNetmask := ^v&(^v+1) == 0 // ones followed by zeros
+	isWildcard := v&(v+1) == 0  // zeros followed by ones
+	if v == 0 && !a.IsUnspecified() {
+		isNetmask = false
+	}
+	switch {
+	case isNetmask:
+		return bits.OnesCount32(v), nil
+	case isWildcard:
+		return 32 - bits.OnesCount32(v), nil
+	}
+	return 0, fmt.Errorf("%s is neither a netmask nor a wildcard mask", mask)
+}
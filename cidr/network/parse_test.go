@@ -0,0 +1,75 @@
+package network
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// Every notation in the ParsePrefix doc comment is listed here, together with
+// the look-alikes it must reject: ranges that are not one CIDR and non-contiguous masks.
+func TestParsePrefix(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string // "" means an error is expected
+	}{
+		{"10.1.0.0/22", "10.1.0.0/22"},
+		{" 10.1.0.5/22 ", "10.1.0.5/22"},
+		{"10.1.0.0 255.255.252.0", "10.1.0.0/22"},
+		{"10.1.0.0/255.255.252.0", "10.1.0.0/22"},
+		{"10.1.0.0 0.0.3.255", "10.1.0.0/22"},
+		{"10.1.0.5 0.0.0.0", "10.1.0.5/32"},
+		{"0.0.0.0 0.0.0.0", "0.0.0.0/0"},
+		{"10.1.0.1", "10.1.0.1/32"},
+		{"2001:db8::1", "2001:db8::1/128"},
+		{"fe80::1%eth0/64", "fe80::1/64"},
+		{"fe80::1%eth0", "fe80::1/128"},
+		{"10.1.0.0-10.1.3.255", "10.1.0.0/22"},
+		{"10.1.0.0-10.1.3.254", ""},
+		{"10.0.0.0 255.0.255.0", ""},
+		{"2001:db8:: ffff::", ""},
+		{"10.0.0.0/33", ""},
+		{"bad", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePrefix(tt.in)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf(`ParsePrefix(%q) = %s, want an error`, tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf(`ParsePrefix(%q) returned error: %s`, tt.in, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf(`ParsePrefix(%q) = %s, want %s`, tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// Unlike ParsePrefix, a range that needs several prefixes is accepted.
+func TestParsePrefixes(t *testing.T) {
+	// act
+	got, err := ParsePrefixes("10.0.0.0-10.0.0.20")
+
+	// assert
+	if err != nil {
+		t.Fatalf(`ParsePrefixes returned error: %s`, err)
+	}
+	if want := prefixes(t, "10.0.0.0/28", "10.0.0.16/30", "10.0.0.20/32"); !slices.Equal(got, want) {
+		t.Errorf(`ParsePrefixes = %v, want %v`, got, want)
+	}
+}
+
+// 10.0.0.0/24 passes, and the same prefix with a host bit set does not.
+func TestCheckHostBits(t *testing.T) {
+	if err := CheckHostBits(netip.MustParsePrefix("10.0.0.0/24")); err != nil {
+		t.Errorf(`CheckHostBits(10.0.0.0/24) returned error: %s`, err)
+	}
+	if err := CheckHostBits(netip.MustParsePrefix("10.0.0.1/24")); err == nil {
+		t.Errorf(`CheckHostBits(10.0.0.1/24) returned no error`)
+	}
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network/v4"
+	"github.com/jokarl/go-learning-projects/cidr/network/v6"
+)
+
+// Range is an inclusive span of addresses of the same family.
+type Range struct {
+	First netip.Addr `json:"first"`
+	Last  netip.Addr `json:"last"`
+}
+
+func (r Range) String() string {
+	return r.First.String() + " - " + r.Last.String()
+}
+
+// ParseRange parses a range written as "first-last", "first - last" or "first last".
+func ParseRange(s string) (Range, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		parts = strings.Fields(s)
+	}
+	if len(parts) != 2 {
+		return Range{}, fmt.Errorf("invalid range %q: expected <first>-<last>", s)
+	}
+
+	first, err := netip.ParseAddr(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+	last, err := netip.ParseAddr(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+	}
+
+	r := Range{First: first, Last: last}
+	if first.Is4() != last.Is4() {
+		return Range{}, fmt.Errorf("invalid range %q: mixed address families", s)
+	}
+	if last.Less(first) {
+		return Range{}, fmt.Errorf("invalid range %q: first address is after last address", s)
+	}
+	return r, nil
+}
+
+// Prefixes returns the smallest list of prefixes that covers exactly the range.
+func (r Range) Prefixes() []netip.Prefix {
+	if r.First.Is4() {
+		return v4.RangeToPrefixes(r.First, r.Last)
+	}
+	return v6.RangeToPrefixes(r.First, r.Last)
+}
+
+// PrefixRange returns the first and last address of p.
+func PrefixRange(p netip.Prefix) Range {
+	p = p.Masked()
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> uint(i%8)
+	}
+	last, _ := netip.AddrFromSlice(b)
+	return Range{First: p.Addr(), Last: last}
+}
+
+// Ranges returns the addresses covered by ps as the smallest list of ranges.
+// Overlapping and adjacent prefixes end up in the same range.
+func Ranges(ps []netip.Prefix) []Range {
+	var out []Range
+	for _, p := range Aggregate(ps) {
+		r := PrefixRange(p)
+		if n := len(out); n > 0 && out[n-1].Last.Next() == r.First {
+			out[n-1].Last = r.Last
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
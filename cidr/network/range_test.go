@@ -0,0 +1,63 @@
+package network
+
+import (
+	"slices"
+	"testing"
+)
+
+// Unaligned ranges need several prefixes, and ranges that reach the end of the
+// address space must not overflow when the last address is incremented.
+func TestRangePrefixes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"v4", "10.0.0.5-10.0.0.20", []string{"10.0.0.5/32", "10.0.0.6/31", "10.0.0.8/29", "10.0.0.16/30", "10.0.0.20/32"}},
+		{"v4 whole space", "0.0.0.0 - 255.255.255.255", []string{"0.0.0.0/0"}},
+		{"v4 top", "255.255.255.254 255.255.255.255", []string{"255.255.255.254/31"}},
+		{"v6", "2001:db8::-2001:db8::1:ffff", []string{"2001:db8::/111"}},
+		{"v6 whole space", ":: - ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", []string{"::/0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseRange(tt.in)
+			if err != nil {
+				t.Fatalf(`ParseRange(%q) returned error: %s`, tt.in, err)
+			}
+			want := prefixes(t, tt.want...)
+			got := r.Prefixes()
+			if !slices.Equal(got, want) {
+				t.Errorf(`Range(%q).Prefixes() = %v, want match for %v`, tt.in, got, want)
+			}
+		})
+	}
+}
+
+// Reversed ranges, mixed families and single addresses are not ranges.
+func TestParseRange(t *testing.T) {
+	for _, in := range []string{"10.0.0.9-10.0.0.1", "10.0.0.1-2001:db8::1", "10.0.0.1", "10.0.0.1-x"} {
+		if _, err := ParseRange(in); err == nil {
+			t.Errorf(`ParseRange(%q) = nil error, want error`, in)
+		}
+	}
+}
+
+// A /25 directly after a /24 continues its range; a gap starts a new one.
+func TestRanges(t *testing.T) {
+	// arrange
+	in := prefixes(t, "10.0.1.0/25", "10.0.0.0/24", "10.0.3.0/24", "2001:db8::/64")
+	want := []string{"10.0.0.0 - 10.0.1.127", "10.0.3.0 - 10.0.3.255", "2001:db8:: - 2001:db8::ffff:ffff:ffff:ffff"}
+
+	// act
+	r := Ranges(in)
+
+	// assert
+	got := make([]string, len(r))
+	for i := range r {
+		got[i] = r[i].String()
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf(`Ranges(%v) = %v, want match for %v`, in, got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ReverseName returns the PTR owner name of a, e.g. "4.2.0.192.in-addr.arpa.".
+func ReverseName(a netip.Addr) string {
+	return ZoneName(netip.PrefixFrom(a, a.BitLen()))
+}
+
+// ZoneName returns the reverse DNS zone name of p, e.g. "2.0.192.in-addr.arpa." for 192.0.2.0/24.
+// Only the octets (v4) or nibbles (v6) fully covered by the prefix length are used, so p
+// should lie on a zone boundary as returned by ReverseZones.
+func ZoneName(p netip.Prefix) string {
+	p = p.Masked()
+	b := p.Addr().AsSlice()
+
+	var labels []string
+	if p.Addr().Is4() {
+		for i := p.Bits()/8 - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(b[i])))
+		}
+		return strings.Join(append(labels, "in-addr.arpa."), ".")
+	}
+	for i := p.Bits()/4 - 1; i >= 0; i-- {
+		nibble := b[i/2] >> 4
+		if i%2 == 1 {
+			nibble = b[i/2] & 0x0f
+		}
+		labels = append(labels, strconv.FormatUint(uint64(nibble), 16))
+	}
+	return strings.Join(append(labels, "ip6.arpa."), ".")
+}
+
+// ReverseZones returns the delegatable reverse DNS zones that cover p.
+// Zones fall on octet boundaries for v4 and nibble boundaries for v6, so a prefix
+// that is not on a boundary is split into the zones of the next longer boundary,
+// e.g. 10.0.0.0/23 becomes two /24 zones. Prefixes longer than the last zone boundary
+// (/24 for v4, /124 for v6) cannot be delegated on their own and return the zone
+// containing them; for v4, see ClasslessZoneName.
+func ReverseZones(p netip.Prefix) []netip.Prefix {
+	p = p.Masked()
+	boundary := 4
+	if p.Addr().Is4() {
+		boundary = 8
+	}
+	if last := p.Addr().BitLen() - boundary; p.Bits() > last {
+		return []netip.Prefix{netip.PrefixFrom(p.Addr(), last).Masked()}
+	}
+
+	zoneBits := (p.Bits() + boundary - 1) / boundary * boundary
+	n, _ := New(p.String())
+	zones, _ := n.Divide(1<<(zoneBits-p.Bits()), false)
+	return zones
+}
+
+// ClasslessZoneName returns the RFC 2317 zone name for a v4 prefix longer than /24,
+// e.g. "0/26.2.0.192.in-addr.arpa." for 192.0.2.0/26.
+func ClasslessZoneName(p netip.Prefix) (string, error) {
+	p = p.Masked()
+	if !p.Addr().Is4() || p.Bits() <= 24 {
+		return "", fmt.Errorf("classless delegation applies to IPv4 prefixes longer than /24, got %s", p)
+	}
+	b := p.Addr().As4()
+	parent := ZoneName(netip.PrefixFrom(p.Addr(), 24))
+	return fmt.Sprintf("%d/%d.%s", b[3], p.Bits(), parent), nil
+}
+
+// ClasslessCNAME returns the owner and target of the RFC 2317 CNAME record that
+// points the PTR name of a in the parent /24 zone into the classless zone.
+func ClasslessCNAME(p netip.Prefix, a netip.Addr) (owner, target string, err error) {
+	zone, err := ClasslessZoneName(p)
+	if err != nil {
+		return "", "", err
+	}
+	if !p.Contains(a) {
+		return "", "", fmt.Errorf("%s is not in %s", a, p)
+	}
+	last := a.As4()[3]
+	return ReverseName(a), fmt.Sprintf("%d.%s", last, zone), nil
+}
@@ -0,0 +1,90 @@
+package network
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// Prefixes off an octet or nibble boundary are covered by several zones,
+// one per value of the partial octet or nibble.
+func TestReverseZones(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"10.0.0.0/8", []string{"10.in-addr.arpa."}},
+		{"192.0.2.0/24", []string{"2.0.192.in-addr.arpa."}},
+		{"10.0.0.0/23", []string{"0.0.10.in-addr.arpa.", "1.0.10.in-addr.arpa."}},
+		{"172.16.0.0/15", []string{"16.172.in-addr.arpa.", "17.172.in-addr.arpa."}},
+		{"192.0.2.64/26", []string{"2.0.192.in-addr.arpa."}},
+		{"0.0.0.0/0", []string{"in-addr.arpa."}},
+		{"2001:db8::/32", []string{"8.b.d.0.1.0.0.2.ip6.arpa."}},
+		{"2001:db8::/35", []string{
+			"0.8.b.d.0.1.0.0.2.ip6.arpa.",
+			"1.8.b.d.0.1.0.0.2.ip6.arpa.",
+		}},
+		{"2001:db8:abcd::/48", []string{"d.c.b.a.8.b.d.0.1.0.0.2.ip6.arpa."}},
+		{"2001:db8::ff/128", []string{"f.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			zones := ReverseZones(netip.MustParsePrefix(tt.prefix))
+			if len(zones) != len(tt.want) {
+				t.Fatalf(`ReverseZones(%s) = %v, want %d zones`, tt.prefix, zones, len(tt.want))
+			}
+			for i, z := range zones {
+				if got := ZoneName(z); got != tt.want[i] {
+					t.Errorf(`ZoneName(ReverseZones(%s)[%d]) = %s, want %s`, tt.prefix, i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// v6 names list every nibble, including the zeros that :: leaves out.
+func TestReverseName(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"192.0.2.4", "4.2.0.192.in-addr.arpa."},
+		{"2001:db8::1", "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa."},
+	}
+	for _, tt := range tests {
+		got := ReverseName(netip.MustParseAddr(tt.addr))
+		if got != tt.want {
+			t.Errorf(`ReverseName(%s) = %s, want %s`, tt.addr, got, tt.want)
+		}
+	}
+}
+
+// RFC 2317 only applies to v4 prefixes longer than /24; anything else,
+// and addresses outside the prefix, must be rejected.
+func TestClassless(t *testing.T) {
+	tests := []struct {
+		prefix string
+		addr   string
+		zone   string // "" if the prefix cannot be delegated classless
+		owner  string
+		target string // "" if the address is rejected
+	}{
+		{"192.0.2.64/26", "192.0.2.65", "64/26.2.0.192.in-addr.arpa.", "65.2.0.192.in-addr.arpa.", "65.64/26.2.0.192.in-addr.arpa."},
+		{"192.0.2.0/25", "192.0.2.127", "0/25.2.0.192.in-addr.arpa.", "127.2.0.192.in-addr.arpa.", "127.0/25.2.0.192.in-addr.arpa."},
+		{"192.0.2.8/29", "192.0.2.16", "8/29.2.0.192.in-addr.arpa.", "", ""},
+		{"192.0.2.0/24", "192.0.2.1", "", "", ""},
+		{"2001:db8::/126", "2001:db8::1", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			p := netip.MustParsePrefix(tt.prefix)
+			zone, zoneErr := ClasslessZoneName(p)
+			owner, target, cnameErr := ClasslessCNAME(p, netip.MustParseAddr(tt.addr))
+			if (zoneErr == nil) != (tt.zone != "") || zone != tt.zone {
+				t.Errorf(`ClasslessZoneName(%s) = %q, %v, want %q`, tt.prefix, zone, zoneErr, tt.zone)
+			}
+			if (cnameErr == nil) != (tt.target != "") || owner != tt.owner || target != tt.target {
+				t.Errorf(`ClasslessCNAME(%s, %s) = %q, %q, %v, want %q, %q`, tt.prefix, tt.addr, owner, target, cnameErr, tt.owner, tt.target)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+
+	"github.com/jokarl/go-learning-projects/cidr/network/v4"
+	"github.com/jokarl/go-learning-projects/cidr/network/v6"
+)
+
+// Aggregate returns the smallest list of prefixes that covers exactly the same
+// addresses as ps. IPv4 and IPv6 prefixes may be mixed; the result lists all
+// IPv4 prefixes first, and each family is sorted by address.
+func Aggregate(ps []netip.Prefix) []netip.Prefix {
+	v4s, v6s := partition(ps)
+	return append(v4.Aggregate(v4s), v6.Aggregate(v6s)...)
+}
+
+// Exclude returns the addresses covered by ps but not by remove,
+// as the smallest list of prefixes. IPv4 prefixes are listed first.
+func Exclude(ps, remove []netip.Prefix) []netip.Prefix {
+	ps4, ps6 := partition(ps)
+	rm4, rm6 := partition(remove)
+	return append(v4.Exclude(ps4, rm4), v6.Exclude(ps6, rm6)...)
+}
+
+// Intersect returns the addresses covered by both a and b,
+// as the smallest list of prefixes. IPv4 prefixes are listed first.
+func Intersect(a, b []netip.Prefix) []netip.Prefix {
+	a4, a6 := partition(a)
+	b4, b6 := partition(b)
+	return append(v4.Intersect(a4, b4), v6.Intersect(a6, b6)...)
+}
+
+// Complement returns the addresses not covered by ps, as the smallest list of prefixes.
+// Only the address families present in ps are considered, so the complement
+// of an IPv4 list does not include the entire IPv6 address space.
+func Complement(ps []netip.Prefix) []netip.Prefix {
+	ps4, ps6 := partition(ps)
+	var universe []netip.Prefix
+	if len(ps4) > 0 {
+		universe = append(universe, netip.PrefixFrom(netip.IPv4Unspecified(), 0))
+	}
+	if len(ps6) > 0 {
+		universe = append(universe, netip.PrefixFrom(netip.IPv6Unspecified(), 0))
+	}
+	return Exclude(universe, ps)
+}
+
+// SetDiff is how the addresses covered by a list of prefixes changed.
+type SetDiff struct {
+	Gained    []netip.Prefix
+	Lost      []netip.Prefix
+	Unchanged []netip.Prefix
+}
+
+// Equivalent reports whether both lists cover the same addresses.
+func (d SetDiff) Equivalent() bool {
+	return len(d.Gained) == 0 && len(d.Lost) == 0
+}
+
+// Diff compares the addresses covered by before and after rather than the prefixes
+// themselves, so lists that aggregate to the same prefixes are equivalent.
+// Each part is the smallest list of prefixes, with IPv4 prefixes listed first.
+func Diff(before, after []netip.Prefix) SetDiff {
+	return SetDiff{
+		Gained:    Exclude(after, before),
+		Lost:      Exclude(before, after),
+		Unchanged: Intersect(before, after),
+	}
+}
+
+// Allocate carves a block with the given prefix length out of free, the free space
+// left in parent, using the best-fit strategy of VLSM. It returns the block and the
+// remaining free space. The address family is that of parent, so a parent without
+// free space reports that no block is free rather than guessing the family.
+func Allocate(parent netip.Prefix, free []netip.Prefix, bits int) (netip.Prefix, []netip.Prefix, error) {
+	free4, free6 := partition(free)
+	if parent.Addr().Is4() {
+		if len(free6) > 0 {
+			return netip.Prefix{}, nil, fmt.Errorf("cannot allocate v6 space from %s", parent)
+		}
+		return v4.Allocate(free4, bits)
+	}
+	if len(free4) > 0 {
+		return netip.Prefix{}, nil, fmt.Errorf("cannot allocate v4 space from %s", parent)
+	}
+	return v6.Allocate(free6, bits)
+}
+
+// Size returns the number of distinct addresses covered by ps.
+func Size(ps []netip.Prefix) *big.Int {
+	total := new(big.Int)
+	for _, p := range Aggregate(ps) {
+		total.Add(total, new(big.Int).Lsh(big.NewInt(1), uint(p.Addr().BitLen()-p.Bits())))
+	}
+	return total
+}
+
+// partition splits ps by address family, dropping invalid prefixes.
+func partition(ps []netip.Prefix) (v4s, v6s []netip.Prefix) {
+	for _, p := range ps {
+		switch {
+		case !p.IsValid():
+			continue
+		case p.Addr().Is4():
+			v4s = append(v4s, p)
+		default:
+			v6s = append(v6s, p)
+		}
+	}
+	return v4s, v6s
+}
@@ -0,0 +1,185 @@
+package network
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func prefixes(t *testing.T, ss ...string) []netip.Prefix {
+	t.Helper()
+	out := make([]netip.Prefix, len(ss))
+	for i, s := range ss {
+		out[i] = netip.MustParsePrefix(s)
+	}
+	return out
+}
+
+// Only aligned neighbours merge: 10.0.1.0/24 and 10.0.2.0/24 are adjacent
+// but not one /23, and v4 and v6 prefixes are aggregated separately.
+func TestAggregate(t *testing.T) {
+	t.Run("buddies", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "10.0.0.128/25", "10.0.0.0/25", "10.0.1.0/24")
+		want := prefixes(t, "10.0.0.0/23")
+
+		// act
+		r := Aggregate(in)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Aggregate(%v) = %v, want match for %v`, in, r, want)
+		}
+	})
+
+	t.Run("duplicates and covered", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "10.0.0.0/8", "10.1.0.0/16", "10.0.0.0/8", "10.2.3.4/32", "192.168.0.0/24")
+		want := prefixes(t, "10.0.0.0/8", "192.168.0.0/24")
+
+		// act
+		r := Aggregate(in)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Aggregate(%v) = %v, want match for %v`, in, r, want)
+		}
+	})
+
+	t.Run("mixed families", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "2001:db8:8000::/33", "10.0.0.1/32", "2001:db8::/33", "10.0.0.0/32")
+		want := prefixes(t, "10.0.0.0/31", "2001:db8::/32")
+
+		// act
+		r := Aggregate(in)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Aggregate(%v) = %v, want match for %v`, in, r, want)
+		}
+	})
+
+	t.Run("not aligned", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "10.0.1.0/24", "10.0.2.0/24")
+		want := prefixes(t, "10.0.1.0/24", "10.0.2.0/24")
+
+		// act
+		r := Aggregate(in)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Aggregate(%v) = %v, want match for %v`, in, r, want)
+		}
+	})
+}
+
+// Removing a block from the middle leaves the pieces on either side of it,
+// and a prefix removed entirely takes nothing of the other family with it.
+func TestExclude(t *testing.T) {
+	t.Run("hole", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "10.0.0.0/22")
+		remove := prefixes(t, "10.0.1.0/24")
+		want := prefixes(t, "10.0.0.0/24", "10.0.2.0/23")
+
+		// act
+		r := Exclude(in, remove)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Exclude(%v, %v) = %v, want match for %v`, in, remove, r, want)
+		}
+	})
+
+	t.Run("covered", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "10.0.1.0/24", "2001:db8::/48")
+		remove := prefixes(t, "10.0.0.0/16")
+		want := prefixes(t, "2001:db8::/48")
+
+		// act
+		r := Exclude(in, remove)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Exclude(%v, %v) = %v, want match for %v`, in, remove, r, want)
+		}
+	})
+
+	t.Run("v6", func(t *testing.T) {
+		// arrange
+		in := prefixes(t, "2001:db8::/32")
+		remove := prefixes(t, "2001:db8:8000::/33", "2001:db8:4000::/34")
+		want := prefixes(t, "2001:db8::/34")
+
+		// act
+		r := Exclude(in, remove)
+
+		// assert
+		if !slices.Equal(r, want) {
+			t.Errorf(`Exclude(%v, %v) = %v, want match for %v`, in, remove, r, want)
+		}
+	})
+}
+
+// Prefixes that only appear in one list drop out, and of two nested ones the smaller is kept.
+func TestIntersect(t *testing.T) {
+	// arrange
+	a := prefixes(t, "10.0.0.0/8", "192.168.1.0/24", "2001:db8::/32")
+	b := prefixes(t, "10.1.0.0/16", "192.168.0.0/16", "172.16.0.0/12")
+	want := prefixes(t, "10.1.0.0/16", "192.168.1.0/24")
+
+	// act
+	r := Intersect(a, b)
+
+	// assert
+	if !slices.Equal(r, want) {
+		t.Errorf(`Intersect(%v, %v) = %v, want match for %v`, a, b, r, want)
+	}
+}
+
+// The complement only spans the families present in the input,
+// so v4-only input does not produce ::/0.
+func TestComplement(t *testing.T) {
+	// arrange
+	in := prefixes(t, "128.0.0.0/1", "64.0.0.0/2")
+	want := prefixes(t, "0.0.0.0/2")
+
+	// act
+	r := Complement(in)
+
+	// assert
+	if !slices.Equal(r, want) {
+		t.Errorf(`Complement(%v) = %v, want match for %v`, in, r, want)
+	}
+}
+
+// Lists are compared by the addresses they cover: two /24s and the /23 they
+// aggregate to are equivalent even though no line is the same.
+func TestDiff(t *testing.T) {
+	// arrange
+	before := prefixes(t, "10.0.0.0/24", "10.0.1.0/24", "192.168.0.0/24", "2001:db8::/48")
+	after := prefixes(t, "10.0.0.0/23", "192.168.0.0/25", "172.16.0.0/24", "2001:db8::/48")
+
+	// act
+	d := Diff(before, after)
+
+	// assert
+	if want := prefixes(t, "172.16.0.0/24"); !slices.Equal(d.Gained, want) {
+		t.Errorf(`Gained = %v, want match for %v`, d.Gained, want)
+	}
+	if want := prefixes(t, "192.168.0.128/25"); !slices.Equal(d.Lost, want) {
+		t.Errorf(`Lost = %v, want match for %v`, d.Lost, want)
+	}
+	if want := prefixes(t, "10.0.0.0/23", "192.168.0.0/25", "2001:db8::/48"); !slices.Equal(d.Unchanged, want) {
+		t.Errorf(`Unchanged = %v, want match for %v`, d.Unchanged, want)
+	}
+	if d.Equivalent() {
+		t.Errorf(`Equivalent() = true, want false`)
+	}
+	if d := Diff(prefixes(t, "10.0.0.0/24", "10.0.1.0/24"), prefixes(t, "10.0.0.0/23")); !d.Equivalent() {
+		t.Errorf(`Diff of aggregated lists is not equivalent: %+v`, d)
+	}
+}
@@ -0,0 +1,171 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// The functions in this file reproduce Terraform's cidrsubnet, cidrsubnets, cidrhost
+// and cidrnetmask functions, including their error messages, so expressions can be
+// checked without a Terraform console. See
+// https://developer.hashicorp.com/terraform/language/functions/cidrsubnet
+
+// CIDRSubnet calculates the netnum-th subnet of prefix that is newbits longer.
+func CIDRSubnet(prefix string, newbits int, netnum *big.Int) (netip.Prefix, error) {
+	p, err := parseTerraformCIDR(prefix)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	if newbits > 32 {
+		return netip.Prefix{}, fmt.Errorf("may not extend prefix by more than 32 bits")
+	}
+	addrLen := p.Addr().BitLen()
+	newPrefixLen := p.Bits() + newbits
+	if newbits < 0 || newPrefixLen > addrLen {
+		return netip.Prefix{}, fmt.Errorf("insufficient address space to extend prefix of %d by %d", p.Bits(), newbits)
+	}
+	maxNetNum := new(big.Int).Lsh(big.NewInt(1), uint(newbits))
+	if netnum.Sign() < 0 || netnum.Cmp(maxNetNum) >= 0 {
+		return netip.Prefix{}, fmt.Errorf("prefix extension of %d does not accommodate a subnet numbered %d", newbits, netnum)
+	}
+
+	offset := new(big.Int).Lsh(netnum, uint(addrLen-newPrefixLen))
+	base := bigAddr(new(big.Int).Add(addrBig(p.Addr()), offset), addrLen)
+	return netip.PrefixFrom(base, newPrefixLen), nil
+}
+
+// CIDRHost calculates the hostnum-th address of prefix.
+// Negative numbers count back from the end, so -1 is the last address.
+func CIDRHost(prefix string, hostnum *big.Int) (netip.Addr, error) {
+	p, err := parseTerraformCIDR(prefix)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	addrLen := p.Addr().BitLen()
+	maxHostNum := new(big.Int).Lsh(big.NewInt(1), uint(addrLen-p.Bits()))
+	maxHostNum.Sub(maxHostNum, big.NewInt(1))
+
+	// Like Terraform, a negative number is turned into its offset from the start,
+	// and that offset is what the error reports.
+	num := new(big.Int).Set(hostnum)
+	abs := new(big.Int).Set(hostnum)
+	if hostnum.Sign() < 0 {
+		abs.Neg(hostnum)
+		abs.Sub(abs, big.NewInt(1))
+		num.Sub(maxHostNum, abs)
+	}
+	if abs.Cmp(maxHostNum) > 0 {
+		return netip.Addr{}, fmt.Errorf("prefix of %d does not accommodate a host numbered %d", p.Bits(), num)
+	}
+	return bigAddr(new(big.Int).Add(addrBig(p.Addr()), num), addrLen), nil
+}
+
+// CIDRNetmask returns the netmask of a v4 prefix in dotted-decimal notation.
+func CIDRNetmask(prefix string) (netip.Addr, error) {
+	p, err := parseTerraformCIDR(prefix)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	if !p.Addr().Is4() {
+		return netip.Addr{}, fmt.Errorf("IPv6 addresses cannot have a netmask: %s", prefix)
+	}
+	n, err := New(p.String())
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return n.Netmask(), nil
+}
+
+// CIDRSubnets allocates consecutive subnets of prefix, each extending the prefix by the
+// corresponding number of bits. Every subnet starts right after the previous one,
+// aligned to its own size, so the result depends on the order of newbits.
+func CIDRSubnets(prefix string, newbits ...int) ([]netip.Prefix, error) {
+	p, err := parseTerraformCIDR(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(newbits) == 0 {
+		return []netip.Prefix{}, nil
+	}
+	addrLen := p.Addr().BitLen()
+	protocol := "IPv4"
+	if addrLen == 128 {
+		protocol = "IPv6"
+	}
+
+	// Start just before the prefix so the first subnet lands on its base address.
+	current := previousSubnet(p, p.Bits()+newbits[0])
+	out := make([]netip.Prefix, len(newbits))
+	for i, n := range newbits {
+		if n < 1 {
+			return nil, fmt.Errorf("must extend prefix by at least one bit")
+		}
+		if n > 32 {
+			return nil, fmt.Errorf("may not extend prefix by more than 32 bits")
+		}
+		length := p.Bits() + n
+		if length > addrLen {
+			return nil, fmt.Errorf("would extend prefix to %d bits, which is too long for an %s address", length, protocol)
+		}
+
+		next, rollover := nextSubnet(current, length)
+		if rollover || !p.Contains(next.Addr()) {
+			return nil, fmt.Errorf("not enough remaining address space for a subnet with a prefix of %d bits after %s", length, current)
+		}
+		current = next
+		out[i] = current
+	}
+	return out, nil
+}
+
+// previousSubnet returns the bits long prefix just below the start of p,
+// wrapping around at the start of the address space.
+func previousSubnet(p netip.Prefix, bits int) netip.Prefix {
+	prev := p.Addr().Prev()
+	if !prev.IsValid() {
+		prev = PrefixRange(netip.PrefixFrom(p.Addr(), 0)).Last
+	}
+	if bits > prev.BitLen() {
+		// Terraform builds an invalid mask here; the caller rejects the length before using it.
+		return netip.PrefixFrom(prev, prev.BitLen())
+	}
+	q, _ := prev.Prefix(bits)
+	return q
+}
+
+// nextSubnet returns the bits long prefix that follows the end of p.
+// It reports true if the end of the address space was passed.
+func nextSubnet(p netip.Prefix, bits int) (netip.Prefix, bool) {
+	last := PrefixRange(p).Last
+	current, _ := last.Prefix(bits)
+	next := PrefixRange(current).Last.Next()
+	if !next.IsValid() {
+		return netip.PrefixFrom(PrefixRange(netip.PrefixFrom(last, 0)).First, bits), true
+	}
+	return netip.PrefixFrom(next, bits), false
+}
+
+// parseTerraformCIDR parses a prefix the way Terraform reports invalid ones.
+func parseTerraformCIDR(s string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid CIDR expression: invalid CIDR address: %s", s)
+	}
+	return p.Masked(), nil
+}
+
+func addrBig(a netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(a.AsSlice())
+}
+
+// bigAddr converts x to an address of the given length, keeping only the low bits.
+func bigAddr(x *big.Int, bits int) netip.Addr {
+	var b [16]byte
+	x = new(big.Int).And(x, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(bits)), big.NewInt(1)))
+	x.FillBytes(b[16-bits/8:])
+	if bits == 32 {
+		return netip.AddrFrom4([4]byte(b[12:]))
+	}
+	return netip.AddrFrom16(b)
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"math/big"
+	"slices"
+	"testing"
+)
+
+// The Terraform documentation examples, and the arguments Terraform rejects
+// with the same error messages, including extensions of more than 32 bits.
+func TestCIDRSubnet(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		newbits int
+		netnum  int64
+		want    string
+		wantErr string
+	}{
+		{"172.16.0.0/12", 4, 2, "172.18.0.0/16", ""},
+		{"10.1.2.0/24", 4, 15, "10.1.2.240/28", ""},
+		{"fd00:fd12:3456:7890::/56", 16, 162, "fd00:fd12:3456:7800:a200::/72", ""},
+		{"10.0.0.0/30", 3, 0, "", "insufficient address space to extend prefix of 30 by 3"},
+		{"10.0.0.0/24", 2, 4, "", "prefix extension of 2 does not accommodate a subnet numbered 4"},
+		{"not-a-cidr", 2, 0, "", "invalid CIDR expression: invalid CIDR address: not-a-cidr"},
+		{"2001:db8::/32", 33, 0, "", "may not extend prefix by more than 32 bits"},
+		{"2001:db8::/32", 40, 1, "", "may not extend prefix by more than 32 bits"},
+	}
+	for _, tt := range tests {
+		got, err := CIDRSubnet(tt.prefix, tt.newbits, big.NewInt(tt.netnum))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf(`CIDRSubnet(%q, %d, %d) error = %v, want %q`, tt.prefix, tt.newbits, tt.netnum, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf(`CIDRSubnet(%q, %d, %d) = %s, %v, want %s`, tt.prefix, tt.newbits, tt.netnum, got, err, tt.want)
+		}
+	}
+}
+
+// Negative host numbers count back from the end, and the error reports
+// the offset Terraform computes from them.
+func TestCIDRHost(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		hostnum int64
+		want    string
+		wantErr string
+	}{
+		{"10.12.112.0/20", 16, "10.12.112.16", ""},
+		{"10.12.112.0/20", 268, "10.12.113.12", ""},
+		{"10.12.112.0/20", -1, "10.12.127.255", ""},
+		{"fd00:fd12:3456:7890:00a2::/72", 34, "fd00:fd12:3456:7890::22", ""},
+		{"10.0.0.0/30", 4, "", "prefix of 30 does not accommodate a host numbered 4"},
+		{"10.0.0.0/30", -5, "", "prefix of 30 does not accommodate a host numbered -1"},
+	}
+	for _, tt := range tests {
+		got, err := CIDRHost(tt.prefix, big.NewInt(tt.hostnum))
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf(`CIDRHost(%q, %d) error = %v, want %q`, tt.prefix, tt.hostnum, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.String() != tt.want {
+			t.Errorf(`CIDRHost(%q, %d) = %s, %v, want %s`, tt.prefix, tt.hostnum, got, err, tt.want)
+		}
+	}
+}
+
+// Subnets are allocated in order, so a /24 followed by a /20 leaves a gap to align
+// the /20, and 0.0.0.0/0 fails after wrapping around the way Terraform does.
+func TestCIDRSubnets(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		newbits []int
+		want    []string
+		wantErr string
+	}{
+		{"10.1.0.0/16", []int{4, 4, 8, 4}, []string{"10.1.0.0/20", "10.1.16.0/20", "10.1.32.0/24", "10.1.48.0/20"}, ""},
+		{"fd00:fd12:3456:7890::/56", []int{16, 16, 16, 32}, []string{"fd00:fd12:3456:7800::/72", "fd00:fd12:3456:7800:100::/72", "fd00:fd12:3456:7800:200::/72", "fd00:fd12:3456:7800:300::/88"}, ""},
+		// Terraform starts from the subnet before the prefix, which wraps around for the
+		// zero address and is then reported as running out of space.
+		{"0.0.0.0/0", []int{1}, nil, "not enough remaining address space for a subnet with a prefix of 1 bits after 128.0.0.0/1"},
+		{"10.0.0.0/24", []int{1, 1, 1}, nil, "not enough remaining address space for a subnet with a prefix of 25 bits after 10.0.0.128/25"},
+		{"10.0.0.0/24", []int{0}, nil, "must extend prefix by at least one bit"},
+		{"10.0.0.0/8", []int{33}, nil, "may not extend prefix by more than 32 bits"},
+		{"10.0.0.0/24", []int{9}, nil, "would extend prefix to 33 bits, which is too long for an IPv4 address"},
+	}
+	for _, tt := range tests {
+		got, err := CIDRSubnets(tt.prefix, tt.newbits...)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf(`CIDRSubnets(%q, %v) error = %v, want %q`, tt.prefix, tt.newbits, err, tt.wantErr)
+			}
+			continue
+		}
+		if want := prefixes(t, tt.want...); err != nil || !slices.Equal(got, want) {
+			t.Errorf(`CIDRSubnets(%q, %v) = %v, %v, want %v`, tt.prefix, tt.newbits, got, err, want)
+		}
+	}
+}
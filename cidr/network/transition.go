@@ -0,0 +1,148 @@
+package network
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network/v6"
+)
+
+// Transition mechanisms that embed v4 information in v6 addresses.
+const (
+	Mechanism6to4           = "6to4"            // RFC 3056
+	MechanismTeredo         = "teredo"          // RFC 4380
+	MechanismISATAP         = "isatap"          // RFC 5214
+	MechanismIPv4Mapped     = "ipv4-mapped"     // RFC 4291 section 2.5.5.2
+	MechanismIPv4Compatible = "ipv4-compatible" // RFC 4291 section 2.5.5.1, deprecated
+	MechanismNAT64          = "nat64"           // RFC 6052 well-known prefix
+)
+
+var (
+	sixToFourPrefix  = netip.MustParsePrefix("2002::/16")
+	teredoPrefix     = netip.MustParsePrefix("2001::/32")
+	mappedPrefix     = netip.MustParsePrefix("::ffff:0:0/96")
+	compatiblePrefix = netip.MustParsePrefix("::/96")
+	nat64Prefix      = netip.MustParsePrefix("64:ff9b::/96")
+)
+
+// Transition is the v4 information a transition mechanism encoded in a v6 prefix.
+// Fields are only set when the prefix length covers the bits they are stored in,
+// so 2002::/16 is recognised as 6to4 but only 2002:c000:221::/48 and longer carry the v4 address.
+type Transition struct {
+	Mechanism string `json:"mechanism"`
+	// IPv4 is the embedded v4 address: the 6to4 router, the ISATAP or NAT64 host,
+	// or the address of a mapped or compatible address.
+	IPv4 *netip.Addr `json:"ipv4,omitempty"`
+	// Server is the Teredo server.
+	Server *netip.Addr `json:"server,omitempty"`
+	// Flags are the Teredo flags; Cone is the cone NAT flag among them.
+	Flags *uint16 `json:"flags,omitempty"`
+	Cone  *bool   `json:"cone,omitempty"`
+	// Client and Port are the Teredo client's public address and UDP port, de-obfuscated.
+	Client *netip.Addr `json:"client,omitempty"`
+	Port   *uint16     `json:"port,omitempty"`
+}
+
+func (t Transition) String() string {
+	var parts []string
+	if t.IPv4 != nil {
+		parts = append(parts, "IPv4 "+t.IPv4.String())
+	}
+	if t.Server != nil {
+		parts = append(parts, "server "+t.Server.String())
+	}
+	if t.Flags != nil {
+		flags := fmt.Sprintf("flags %#04x", *t.Flags)
+		if *t.Cone {
+			flags += " (cone NAT)"
+		}
+		parts = append(parts, flags)
+	}
+	if t.Client != nil {
+		parts = append(parts, "client "+netip.AddrPortFrom(*t.Client, *t.Port).String())
+	}
+	if len(parts) == 0 {
+		return t.Mechanism
+	}
+	return t.Mechanism + ": " + strings.Join(parts, ", ")
+}
+
+// DecodeTransition recognises v6 prefixes of the 6to4, Teredo, ISATAP, IPv4-mapped,
+// IPv4-compatible and NAT64 well-known prefix mechanisms and decodes what they embed.
+// It reports false for v4 prefixes and v6 prefixes of no known mechanism.
+func DecodeTransition(p netip.Prefix) (Transition, bool) {
+	p = p.Masked()
+	if !p.Addr().Is6() {
+		return Transition{}, false
+	}
+	b := p.Addr().As16()
+	covers := func(bits int) bool { return p.Bits() >= bits }
+	v4 := func(b []byte) *netip.Addr {
+		a := netip.AddrFrom4([4]byte(b))
+		return &a
+	}
+
+	switch {
+	case within(p, mappedPrefix):
+		t := Transition{Mechanism: MechanismIPv4Mapped}
+		if covers(128) {
+			t.IPv4 = v4(b[12:])
+		}
+		return t, true
+
+	case within(p, nat64Prefix):
+		t := Transition{Mechanism: MechanismNAT64}
+		if covers(128) {
+			n, _ := v6.NewNetwork(nat64Prefix.String())
+			if a, err := n.Extract(p.Addr().String()); err == nil {
+				t.IPv4 = &a
+			}
+		}
+		return t, true
+
+	case within(p, sixToFourPrefix):
+		// 2002:V4ADDR::/48
+		t := Transition{Mechanism: Mechanism6to4}
+		if covers(48) {
+			t.IPv4 = v4(b[2:6])
+		}
+		return t, true
+
+	case within(p, teredoPrefix):
+		// 2001:0:SERVER:FLAGS:PORT:CLIENT, with the client port and address inverted.
+		t := Transition{Mechanism: MechanismTeredo}
+		if covers(64) {
+			t.Server = v4(b[4:8])
+		}
+		if covers(80) {
+			flags := uint16(b[8])<<8 | uint16(b[9])
+			cone := flags&0x8000 != 0
+			t.Flags, t.Cone = &flags, &cone
+		}
+		if covers(128) {
+			port := ^(uint16(b[10])<<8 | uint16(b[11]))
+			client := [4]byte{^b[12], ^b[13], ^b[14], ^b[15]}
+			t.Port, t.Client = &port, v4(client[:])
+		}
+		return t, true
+
+	case covers(128) && within(p, compatiblePrefix) && b[12] != 0:
+		// ::a.b.c.d, which excludes :: and ::1.
+		return Transition{Mechanism: MechanismIPv4Compatible, IPv4: v4(b[12:])}, true
+
+	case covers(96) && b[10] == 0x5e && b[11] == 0xfe && b[8]&^0x02 == 0 && b[9] == 0:
+		// ISATAP interface identifiers are 00-00-5E-FE or 02-00-5E-FE followed by the v4 address.
+		t := Transition{Mechanism: MechanismISATAP}
+		if covers(128) {
+			t.IPv4 = v4(b[12:])
+		}
+		return t, true
+	}
+	return Transition{}, false
+}
+
+// within reports whether p lies inside block.
+func within(p, block netip.Prefix) bool {
+	return p.Bits() >= block.Bits() && block.Contains(p.Addr())
+}
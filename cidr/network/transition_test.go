@@ -0,0 +1,39 @@
+package network
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// A prefix too short to hold the embedded address is still recognised, with only
+// the fields it fixes, such as the Teredo server of a /64.
+func TestDecodeTransition(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string // Transition.String(), or "" if not recognised
+	}{
+		{"2002:c000:221::/48", "6to4: IPv4 192.0.2.33"},
+		{"2002::/16", "6to4"},
+		{"2001:0:4136:e378:8000:63bf:3fff:fdd2/128", "teredo: server 65.54.227.120, flags 0x8000 (cone NAT), client 192.0.2.45:40000"},
+		{"2001:0:4136:e378::/64", "teredo: server 65.54.227.120"},
+		{"fe80::200:5efe:c000:201/128", "isatap: IPv4 192.0.2.1"},
+		{"2001:db8::5efe:c000:201/128", "isatap: IPv4 192.0.2.1"},
+		{"::ffff:192.0.2.1/128", "ipv4-mapped: IPv4 192.0.2.1"},
+		{"::192.0.2.1/128", "ipv4-compatible: IPv4 192.0.2.1"},
+		{"::1/128", ""},
+		{"64:ff9b::c000:221/128", "nat64: IPv4 192.0.2.33"},
+		{"2001:db8::/32", ""},
+		{"192.0.2.0/24", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, ok := DecodeTransition(netip.MustParsePrefix(tt.prefix))
+			if ok != (tt.want != "") {
+				t.Fatalf(`DecodeTransition(%s) recognised = %t, want %t`, tt.prefix, ok, tt.want != "")
+			}
+			if ok && got.String() != tt.want {
+				t.Errorf(`DecodeTransition(%s) = %q, want %q`, tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package types
 
 import (
+	"iter"
 	"math/big"
 	"net/netip"
 )
 
 // Network represents an IP network with methods for calculating network properties.
 type Network interface {
+	// Prefix returns the network's prefix with the host bits cleared.
+	Prefix() netip.Prefix
+
 	// BaseAddress returns the network address (first address in the network).
 	BaseAddress() netip.Addr
 
@@ -27,7 +31,16 @@ type Network interface {
 	// Count returns the total number of addresses in this network.
 	Count() *big.Int
 
+	// Hosts returns an iterator over the addresses in the network.
+	// Iteration starts `offset` addresses into the network and advances `step` addresses at a time
+	// (a step of 0 is treated as 1). If usableOnly is true, only the addresses from
+	// FirstUsableAddress to LastUsableAddress are considered; /31, /32, /127 and /128
+	// networks have no reserved addresses, so all of their addresses are usable.
+	// Addresses are computed on demand, so even a /64 can be iterated without allocating.
+	Hosts(usableOnly bool, offset, step uint64) iter.Seq[netip.Addr]
+
 	// Contains checks if the network contains the specified IP addresses.
+	// Invalid addresses and addresses of the other family are reported as not contained.
 	Contains([]string) map[string]bool
 
 	// Divide divides the network into smaller subnets.
@@ -40,6 +53,12 @@ type Network interface {
 	// See https://www.rfc-editor.org/rfc/rfc6052.html#section-2.2
 	Embed(string) (netip.Addr, error)
 
+	// Extract extracts the IPv4 address from an IPv4-embedded IPv6 address in this network.
+	// It is the inverse of Embed and returns an error if the network is not an IPv6 network,
+	// the address is not in the network, or bits 64-71 (the "u" octet) are not zero.
+	// See https://www.rfc-editor.org/rfc/rfc6052.html#section-2.2
+	Extract(string) (netip.Addr, error)
+
 	// VLSM divides the network into subnets of variable lengths based on the provided sizes.
 	// The input is a slice of integers representing the sizes of each subnet.
 	// It returns two slices: one for the allocated prefixes and one for the remaining prefixes.
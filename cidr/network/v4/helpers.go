@@ -24,8 +24,8 @@ func splitOnceV4(p netip.Prefix) (left, right netip.Prefix) {
 	if p.Bits() >= p.Addr().BitLen() {
 		return p, p
 	}
-	size := uint32(1) << uint(32-p.Bits())
-	half := size >> 1
+	// Computed directly, as the size of a /0 does not fit in a uint32.
+	half := uint32(1) << uint(31-p.Bits())
 	base := u32(p.Masked().Addr())
 	left = netip.PrefixFrom(addr4(base), p.Bits()+1)
 	right = netip.PrefixFrom(addr4(base+half), p.Bits()+1)
@@ -79,9 +79,10 @@ func tryMergePairV4(a, b netip.Prefix) (netip.Prefix, bool) {
 	if a.Bits() == 0 {
 		return netip.Prefix{}, false
 	}
-	size := uint32(1) << uint(32-a.Bits())
-	uA := u32(a.Masked().Addr())
-	uB := u32(b.Masked().Addr())
+	// uint64 so that 2*size does not overflow when merging two /1s.
+	size := uint64(1) << uint(32-a.Bits())
+	uA := uint64(u32(a.Masked().Addr()))
+	uB := uint64(u32(b.Masked().Addr()))
 	if uA > uB {
 		uA, uB = uB, uA
 	}
@@ -92,7 +93,7 @@ func tryMergePairV4(a, b netip.Prefix) (netip.Prefix, bool) {
 	if (uA%(2*size) != 0) || (uB%(2*size) != size) {
 		return netip.Prefix{}, false
 	}
-	return netip.PrefixFrom(addr4(uA), a.Bits()-1), true
+	return netip.PrefixFrom(addr4(uint32(uA)), a.Bits()-1), true
 }
 
 func u32(a netip.Addr) uint32 {
@@ -105,3 +106,93 @@ func addr4(u uint32) netip.Addr {
 	binary.BigEndian.PutUint32(b[:], u)
 	return netip.AddrFrom4(b)
 }
+
+// removeCoveredV4 returns a sorted copy of ps without duplicates
+// and without prefixes that are covered by another prefix in ps.
+func removeCoveredV4(ps []netip.Prefix) []netip.Prefix {
+	if len(ps) == 0 {
+		return nil
+	}
+	sorted := make([]netip.Prefix, len(ps))
+	for i := range ps {
+		sorted[i] = ps[i].Masked()
+	}
+	// Shorter prefixes sort first, so a covering prefix is always seen
+	// before the prefixes it contains.
+	sortPrefixesV4(sorted)
+
+	out := make([]netip.Prefix, 0, len(sorted))
+	for _, p := range sorted {
+		if len(out) > 0 && out[len(out)-1].Contains(p.Addr()) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// lastV4 returns the last address of p as uint32.
+func lastV4(p netip.Prefix) uint32 {
+	return u32(p.Masked().Addr()) | ^uint32(0)>>uint(p.Bits())
+}
+
+// overlappingV4 returns the run of prefixes in sorted that overlap p.
+// sorted must be ordered by address and free of overlaps, as returned by removeCoveredV4.
+func overlappingV4(p netip.Prefix, sorted []netip.Prefix) []netip.Prefix {
+	first, last := u32(p.Masked().Addr()), lastV4(p)
+	i := sort.Search(len(sorted), func(i int) bool { return lastV4(sorted[i]) >= first })
+	j := i
+	for j < len(sorted) && u32(sorted[j].Addr()) <= last {
+		j++
+	}
+	return sorted[i:j]
+}
+
+// excludeV4 removes the addresses of remove from p by splitting p
+// until no half partially overlaps a prefix in remove.
+func excludeV4(p netip.Prefix, remove []netip.Prefix) []netip.Prefix {
+	remove = overlappingV4(p, remove)
+	if len(remove) == 0 {
+		return []netip.Prefix{p}
+	}
+	for _, r := range remove {
+		if r.Bits() <= p.Bits() {
+			return nil // p is covered entirely
+		}
+	}
+	l, r := splitOnceV4(p)
+	return append(excludeV4(l, remove), excludeV4(r, remove)...)
+}
+
+// bestFitV4 carves a /wantLen block out of free.
+// Best-fit: pick the smallest free block that can produce wantLen (max Bits() subject to Bits() <= wantLen),
+// preferring the first one in free on ties. The block is split only as needed and the
+// right siblings are kept as free space. It returns the block, the remaining free space,
+// and false if no free block is large enough.
+func bestFitV4(free []netip.Prefix, wantLen int) (netip.Prefix, []netip.Prefix, bool) {
+	idx := -1
+	bestBits := -1
+	for i, b := range free {
+		if b.Bits() <= wantLen && b.Bits() > bestBits {
+			idx = i
+			bestBits = b.Bits()
+		}
+	}
+	if idx == -1 {
+		return netip.Prefix{}, free, false
+	}
+
+	// Pop chosen block.
+	block := free[idx]
+	free = append(free[:idx], free[idx+1:]...)
+
+	// Split only as needed; keep right siblings as free space.
+	cur := block
+	for cur.Bits() < wantLen {
+		l, r := splitOnceV4(cur)
+		// Take left path for allocation; keep the right as free.
+		free = append(free, r)
+		cur = l
+	}
+	return cur, free, true
+}
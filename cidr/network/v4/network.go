@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+	"iter"
 	"math/big"
 	"net/netip"
 	"sort"
 
 	"github.com/jokarl/go-learning-projects/cidr/math"
 	"github.com/jokarl/go-learning-projects/cidr/network/types"
-	"github.com/jokarl/go-learning-projects/cidr/output"
 )
 
 type network struct {
@@ -29,6 +29,10 @@ func NewNetwork(s string) (types.Network, error) {
 	}, nil
 }
 
+func (n *network) Prefix() netip.Prefix {
+	return n.prefix.Masked()
+}
+
 func (n *network) BaseAddress() netip.Addr {
 	return n.prefix.Masked().Addr()
 }
@@ -77,12 +81,38 @@ func (n *network) Count() *big.Int {
 	return big.NewInt(0).Lsh(big.NewInt(1), uint(hostBits))
 }
 
+func (n *network) Hosts(usableOnly bool, offset, step uint64) iter.Seq[netip.Addr] {
+	// uint64 so that stepping past 255.255.255.255 does not wrap around.
+	first := uint64(u32(n.BaseAddress()))
+	last := uint64(u32(*n.BroadcastAddress()))
+	if usableOnly && n.prefix.Bits() < 31 {
+		first++
+		last--
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	return func(yield func(netip.Addr) bool) {
+		if offset > last-first {
+			return
+		}
+		for a := first + offset; a <= last; a += step {
+			if !yield(addr4(uint32(a))) {
+				return
+			}
+			if last-a < step {
+				return
+			}
+		}
+	}
+}
+
 func (n *network) Contains(addrs []string) map[string]bool {
 	r := make(map[string]bool, len(addrs))
 	for _, addr := range addrs {
 		a, err := netip.ParseAddr(addr)
 		if err != nil || !a.Is4() {
-			fmt.Println(output.Yellow, "Warning: Invalid IPv4 address:", addr, output.Reset)
 			r[addr] = false
 			continue
 		}
@@ -95,6 +125,10 @@ func (n *network) Embed(_ string) (netip.Addr, error) {
 	return netip.Addr{}, fmt.Errorf("embedding not supported for IPv4 networks")
 }
 
+func (n *network) Extract(_ string) (netip.Addr, error) {
+	return netip.Addr{}, fmt.Errorf("extracting not supported for IPv4 networks")
+}
+
 func (n *network) Divide(c int, vlsm bool) ([]netip.Prefix, error) {
 	if vlsm {
 		return n.divideVLSM(c)
@@ -204,31 +238,12 @@ func (n *network) VLSM(hostCounts []int) (allocated, leftover []netip.Prefix, er
 			return nil, nil, fmt.Errorf("host count %d too large for IPv4", hosts)
 		}
 
-		// Best-fit: pick the smallest free block that can produce wantLen (max Bits() subject to Bits() <= wantLen).
-		idx := -1
-		bestBits := -1
-		for i, b := range free {
-			if b.Bits() <= wantLen && b.Bits() > bestBits {
-				idx = i
-				bestBits = b.Bits()
-			}
-		}
-		if idx == -1 {
+		var cur netip.Prefix
+		var ok bool
+		cur, free, ok = bestFitV4(free, wantLen)
+		if !ok {
 			return nil, nil, fmt.Errorf("insufficient address space for %d hosts", hosts)
 		}
-
-		// Pop chosen block.
-		block := free[idx]
-		free = append(free[:idx], free[idx+1:]...)
-
-		// Split only as needed; keep right siblings as free space.
-		cur := block
-		for cur.Bits() < wantLen {
-			l, r := splitOnceV4(cur)
-			// Take left path for allocation; keep the right as free.
-			free = append(free, r)
-			cur = l
-		}
 		allocated = append(allocated, cur)
 	}
 
@@ -0,0 +1,75 @@
+package v4
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func addrs(t *testing.T, ss ...string) []netip.Addr {
+	t.Helper()
+	out := make([]netip.Addr, len(ss))
+	for i, s := range ss {
+		out[i] = netip.MustParseAddr(s)
+	}
+	return out
+}
+
+// Offsets and steps must stop at 255.255.255.255 rather than wrap around to 0.0.0.0,
+// and /31 and /32 networks have no reserved addresses to skip.
+func TestHosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		cidr       string
+		usableOnly bool
+		offset     uint64
+		step       uint64
+		want       []string
+	}{
+		{"all", "10.0.0.0/30", false, 0, 0, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"usable", "10.0.0.0/30", true, 0, 0, []string{"10.0.0.1", "10.0.0.2"}},
+		{"offset", "10.0.0.0/29", true, 2, 0, []string{"10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}},
+		{"step", "10.0.0.0/29", false, 1, 3, []string{"10.0.0.1", "10.0.0.4", "10.0.0.7"}},
+		{"offset past end", "10.0.0.0/30", false, 4, 1, nil},
+		{"/31 usable", "10.0.0.0/31", true, 0, 0, []string{"10.0.0.0", "10.0.0.1"}},
+		{"/32 usable", "10.0.0.5/32", true, 0, 0, []string{"10.0.0.5"}},
+		{"step past end of space", "255.255.255.252/30", false, 1, 1 << 40, []string{"255.255.255.253"}},
+		{"offset past end of space", "255.255.255.0/24", false, 1 << 40, 1, nil},
+		{"last address of space", "255.255.255.254/31", false, 1, 1, []string{"255.255.255.255"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNetwork(tt.cidr)
+			if err != nil {
+				t.Fatalf(`NewNetwork(%s) returned error: %s`, tt.cidr, err)
+			}
+			got := slices.Collect(n.Hosts(tt.usableOnly, tt.offset, tt.step))
+			if want := addrs(t, tt.want...); !slices.Equal(got, want) {
+				t.Errorf(`Hosts(%t, %d, %d) of %s = %v, want %v`, tt.usableOnly, tt.offset, tt.step, tt.cidr, got, want)
+			}
+		})
+	}
+}
+
+// Breaking out of the loop over a /8 must stop the iterator after two addresses.
+func TestHostsBreak(t *testing.T) {
+	// arrange
+	n, err := NewNetwork("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf(`NewNetwork returned error: %s`, err)
+	}
+
+	// act
+	var got []netip.Addr
+	for a := range n.Hosts(true, 0, 1) {
+		got = append(got, a)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	// assert
+	if want := addrs(t, "10.0.0.1", "10.0.0.2"); !slices.Equal(got, want) {
+		t.Errorf(`Hosts = %v, want %v`, got, want)
+	}
+}
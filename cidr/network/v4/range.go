@@ -0,0 +1,30 @@
+package v4
+
+import (
+	"math/bits"
+	"net/netip"
+)
+
+// RangeToPrefixes returns the smallest list of IPv4 prefixes that covers
+// exactly the addresses from first to last, inclusive, sorted by address.
+// It returns nil if first is greater than last.
+func RangeToPrefixes(first, last netip.Addr) []netip.Prefix {
+	// uint64 so that the size of a /0 and the address after
+	// 255.255.255.255 can be represented.
+	lo, hi := uint64(u32(first)), uint64(u32(last))
+
+	var out []netip.Prefix
+	for lo <= hi {
+		// Largest block that starts at lo (alignment) and does not run past hi.
+		hostBits := 32
+		if lo != 0 {
+			hostBits = bits.TrailingZeros64(lo)
+		}
+		for uint64(1)<<uint(hostBits) > hi-lo+1 {
+			hostBits--
+		}
+		out = append(out, netip.PrefixFrom(addr4(uint32(lo)), 32-hostBits))
+		lo += uint64(1) << uint(hostBits)
+	}
+	return out
+}
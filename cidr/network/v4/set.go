@@ -0,0 +1,59 @@
+package v4
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Aggregate returns the smallest list of IPv4 prefixes that covers exactly
+// the same addresses as ps, sorted by address. Duplicates and prefixes
+// covered by a larger prefix are dropped before sibling blocks are merged.
+// The input slice is not modified.
+func Aggregate(ps []netip.Prefix) []netip.Prefix {
+	return coalesceV4(removeCoveredV4(ps))
+}
+
+// Exclude returns the addresses covered by ps but not by remove,
+// as the smallest list of IPv4 prefixes sorted by address.
+func Exclude(ps, remove []netip.Prefix) []netip.Prefix {
+	remove = removeCoveredV4(remove)
+	var out []netip.Prefix
+	for _, p := range removeCoveredV4(ps) {
+		out = append(out, excludeV4(p, remove)...)
+	}
+	return coalesceV4(out)
+}
+
+// Intersect returns the addresses covered by both a and b,
+// as the smallest list of IPv4 prefixes sorted by address.
+func Intersect(a, b []netip.Prefix) []netip.Prefix {
+	b = removeCoveredV4(b)
+	var out []netip.Prefix
+	for _, p := range removeCoveredV4(a) {
+		// Two prefixes either nest or are disjoint, so the
+		// shared part of an overlap is always the longer prefix.
+		for _, r := range overlappingV4(p, b) {
+			if r.Bits() >= p.Bits() {
+				out = append(out, r)
+			} else {
+				out = append(out, p)
+			}
+		}
+	}
+	return coalesceV4(out)
+}
+
+// Allocate carves a block with the given prefix length out of the free IPv4 prefixes
+// using the same best-fit strategy as VLSM: the smallest free block that fits is split,
+// and the lowest address is taken on ties. It returns the allocated block and the
+// remaining free space, coalesced and sorted by address. The input slice is not modified.
+func Allocate(free []netip.Prefix, bits int) (netip.Prefix, []netip.Prefix, error) {
+	if bits < 0 || bits > 32 {
+		return netip.Prefix{}, nil, fmt.Errorf("invalid prefix length /%d", bits)
+	}
+	p, rest, ok := bestFitV4(removeCoveredV4(free), bits)
+	if !ok {
+		return netip.Prefix{}, nil, fmt.Errorf("no free block of /%d", bits)
+	}
+	return p, coalesceV4(rest), nil
+}
@@ -2,7 +2,9 @@ package v6
 
 import (
 	"bytes"
+	"encoding/binary"
 	"math/big"
+	"math/bits"
 	"net/netip"
 	"sort"
 )
@@ -106,3 +108,114 @@ func bigToAddr16(x *big.Int) netip.Addr {
 	copy(b[16-len(xb):], xb)
 	return netip.AddrFrom16(b)
 }
+
+// removeCoveredV6 returns a sorted copy of ps without duplicates
+// and without prefixes that are covered by another prefix in ps.
+func removeCoveredV6(ps []netip.Prefix) []netip.Prefix {
+	if len(ps) == 0 {
+		return nil
+	}
+	sorted := make([]netip.Prefix, len(ps))
+	for i := range ps {
+		sorted[i] = ps[i].Masked()
+	}
+	// Shorter prefixes sort first, so a covering prefix is always seen
+	// before the prefixes it contains.
+	sortPrefixesV6(sorted)
+
+	out := make([]netip.Prefix, 0, len(sorted))
+	for _, p := range sorted {
+		if len(out) > 0 && out[len(out)-1].Contains(p.Addr()) {
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// lastV6 returns the last address of p.
+func lastV6(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().As16()
+	for i := p.Bits(); i < 128; i++ {
+		b[i/8] |= 0x80 >> uint(i%8)
+	}
+	return netip.AddrFrom16(b)
+}
+
+// overlappingV6 returns the run of prefixes in sorted that overlap p.
+// sorted must be ordered by address and free of overlaps, as returned by removeCoveredV6.
+func overlappingV6(p netip.Prefix, sorted []netip.Prefix) []netip.Prefix {
+	first, last := p.Masked().Addr(), lastV6(p)
+	i := sort.Search(len(sorted), func(i int) bool { return lastV6(sorted[i]).Compare(first) >= 0 })
+	j := i
+	for j < len(sorted) && sorted[j].Addr().Compare(last) <= 0 {
+		j++
+	}
+	return sorted[i:j]
+}
+
+// excludeV6 removes the addresses of remove from p by splitting p
+// until no half partially overlaps a prefix in remove.
+func excludeV6(p netip.Prefix, remove []netip.Prefix) []netip.Prefix {
+	remove = overlappingV6(p, remove)
+	if len(remove) == 0 {
+		return []netip.Prefix{p}
+	}
+	for _, r := range remove {
+		if r.Bits() <= p.Bits() {
+			return nil // p is covered entirely
+		}
+	}
+	l, r := splitOnceV6(p)
+	return append(excludeV6(l, remove), excludeV6(r, remove)...)
+}
+
+// bestFitV6 carves a /wantLen block out of free.
+// Best-fit: pick the smallest free block that can produce wantLen (max Bits() subject to Bits() <= wantLen),
+// preferring the first one in free on ties. The block is split only as needed and the
+// right siblings are kept as free space. It returns the block, the remaining free space,
+// and false if no free block is large enough.
+func bestFitV6(free []netip.Prefix, wantLen int) (netip.Prefix, []netip.Prefix, bool) {
+	idx := -1
+	bestBits := -1
+	for i, b := range free {
+		if b.Bits() <= wantLen && b.Bits() > bestBits {
+			idx = i
+			bestBits = b.Bits()
+		}
+	}
+	if idx == -1 {
+		return netip.Prefix{}, free, false
+	}
+
+	// Pop chosen block.
+	block := free[idx]
+	free = append(free[:idx], free[idx+1:]...)
+
+	// Split only as needed; keep right siblings as free space.
+	cur := block
+	for cur.Bits() < wantLen {
+		l, r := splitOnceV6(cur)
+		// Take left path for allocation; keep the right as free.
+		free = append(free, r)
+		cur = l
+	}
+	return cur, free, true
+}
+
+// addUint64 returns a+n, and true if the result overflows the IPv6 address space.
+// It works on the two 64-bit halves directly, which avoids big.Int allocations
+// when iterating over large networks.
+func addUint64(a netip.Addr, n uint64) (netip.Addr, bool) {
+	b := a.As16()
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+
+	var carry uint64
+	lo, carry = bits.Add64(lo, n, 0)
+	hi, carry = bits.Add64(hi, 0, carry)
+
+	binary.BigEndian.PutUint64(b[:8], hi)
+	binary.BigEndian.PutUint64(b[8:], lo)
+	return netip.AddrFrom16(b), carry != 0
+}
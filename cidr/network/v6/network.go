@@ -3,6 +3,7 @@ package v6
 import (
 	"bytes"
 	"fmt"
+	"iter"
 	"math/big"
 	"net/netip"
 	"sort"
@@ -27,6 +28,10 @@ func NewNetwork(s string) (types.Network, error) {
 	}, nil
 }
 
+func (n *network) Prefix() netip.Prefix {
+	return n.prefix.Masked()
+}
+
 func (n *network) BaseAddress() netip.Addr {
 	return n.prefix.Masked().Addr()
 }
@@ -93,12 +98,33 @@ func (n *network) Count() *big.Int {
 	return big.NewInt(0).Lsh(big.NewInt(1), uint(hostBits))
 }
 
+func (n *network) Hosts(usableOnly bool, offset, step uint64) iter.Seq[netip.Addr] {
+	first := n.BaseAddress()
+	last := lastV6(n.prefix)
+	if usableOnly && n.prefix.Bits() < 127 {
+		first = n.FirstUsableAddress()
+		last = n.LastUsableAddress()
+	}
+	if step == 0 {
+		step = 1
+	}
+
+	return func(yield func(netip.Addr) bool) {
+		a, overflow := addUint64(first, offset)
+		for !overflow && a.Compare(last) <= 0 {
+			if !yield(a) {
+				return
+			}
+			a, overflow = addUint64(a, step)
+		}
+	}
+}
+
 func (n *network) Contains(addrs []string) map[string]bool {
 	r := make(map[string]bool, len(addrs))
 	for _, addr := range addrs {
 		a, err := netip.ParseAddr(addr)
 		if err != nil || !a.Is6() {
-			fmt.Println("Warning: Invalid IPv6 address:", addr)
 			r[addr] = false
 			continue
 		}
@@ -107,10 +133,12 @@ func (n *network) Contains(addrs []string) map[string]bool {
 	return r
 }
 
+// embedPrefixLengths are the prefix lengths defined for IPv4-embedded IPv6 addresses.
+var embedPrefixLengths = map[int]struct{}{32: {}, 40: {}, 48: {}, 56: {}, 64: {}, 96: {}}
+
 func (n *network) Embed(s string) (netip.Addr, error) {
-	allowed := map[int]struct{}{32: {}, 40: {}, 48: {}, 56: {}, 64: {}, 96: {}}
 	bits := n.prefix.Bits()
-	if _, ok := allowed[bits]; !ok {
+	if _, ok := embedPrefixLengths[bits]; !ok {
 		return netip.Addr{}, fmt.Errorf("invalid prefix length %d for IPv4 embedding; allowed: 32,40,48,56,64,96", bits)
 	}
 
@@ -146,6 +174,50 @@ func (n *network) Embed(s string) (netip.Addr, error) {
 	return netip.AddrFrom16(v6b), nil
 }
 
+func (n *network) Extract(s string) (netip.Addr, error) {
+	bits := n.prefix.Bits()
+	if _, ok := embedPrefixLengths[bits]; !ok {
+		return netip.Addr{}, fmt.Errorf("invalid prefix length %d for IPv4 extraction; allowed: 32,40,48,56,64,96", bits)
+	}
+
+	v6, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, fmt.Errorf("invalid IPv6 address: %w", err)
+	}
+	if !v6.Is6() || v6.Is4In6() {
+		return netip.Addr{}, fmt.Errorf("address is not IPv6: %s", s)
+	}
+	if !n.prefix.Contains(v6) {
+		return netip.Addr{}, fmt.Errorf("%s is not in %s", s, n.prefix.Masked())
+	}
+
+	v6b := v6.As16()
+	if v6b[8] != 0 {
+		return netip.Addr{}, fmt.Errorf("bits 64-71 of %s must be zero, got %#02x", s, v6b[8])
+	}
+
+	var v4b [4]byte
+	switch bits {
+	case 32: // v4 at bytes 4..7
+		copy(v4b[:], v6b[4:8])
+	case 40: // v4[0:3] at 5..7, v4[3] at 9
+		copy(v4b[:3], v6b[5:8])
+		v4b[3] = v6b[9]
+	case 48: // v4[0:2] at 6..7, v4[2:4] at 9..10
+		copy(v4b[:2], v6b[6:8])
+		copy(v4b[2:], v6b[9:11])
+	case 56: // v4[0] at 7, v4[1:4] at 9..11
+		v4b[0] = v6b[7]
+		copy(v4b[1:], v6b[9:12])
+	case 64: // v4 at 9..12
+		copy(v4b[:], v6b[9:13])
+	case 96: // v4 at 12..15
+		copy(v4b[:], v6b[12:16])
+	}
+
+	return netip.AddrFrom4(v4b), nil
+}
+
 func (n *network) Divide(c int, vlsm bool) ([]netip.Prefix, error) {
 	if vlsm {
 		return n.divideVLSM(c)
@@ -280,30 +352,12 @@ func (n *network) VLSM(hostCounts []int) (allocated, leftover []netip.Prefix, er
 			return nil, nil, fmt.Errorf("request %d exceeds IPv6 space", need)
 		}
 
-		// Best-fit: pick the smallest free block that can yield wantLen
-		idx := -1
-		bestBits := -1
-		for i, b := range free {
-			if b.Bits() <= wantLen && b.Bits() > bestBits {
-				idx = i
-				bestBits = b.Bits()
-			}
-		}
-		if idx == -1 {
+		var cur netip.Prefix
+		var ok bool
+		cur, free, ok = bestFitV6(free, wantLen)
+		if !ok {
 			return nil, nil, fmt.Errorf("insufficient address space for %d", need)
 		}
-
-		// Pop chosen block.
-		block := free[idx]
-		free = append(free[:idx], free[idx+1:]...)
-
-		// Split only as needed; keep right siblings as free space.
-		cur := block
-		for cur.Bits() < wantLen {
-			l, r := splitOnceV6(cur)
-			free = append(free, r)
-			cur = l
-		}
 		allocated = append(allocated, cur)
 	}
 
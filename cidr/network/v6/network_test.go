@@ -0,0 +1,141 @@
+package v6
+
+import (
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func addrs(t *testing.T, ss ...string) []netip.Addr {
+	t.Helper()
+	out := make([]netip.Addr, len(ss))
+	for i, s := range ss {
+		out[i] = netip.MustParseAddr(s)
+	}
+	return out
+}
+
+// Like the v4 test, but with steps near the top of the address space,
+// where adding the step to the address would overflow.
+func TestHosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		cidr       string
+		usableOnly bool
+		offset     uint64
+		step       uint64
+		want       []string
+	}{
+		{"all", "2001:db8::/126", false, 0, 0, []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+		{"usable", "2001:db8::/126", true, 0, 0, []string{"2001:db8::1", "2001:db8::2"}},
+		{"offset", "2001:db8::/125", true, 4, 0, []string{"2001:db8::5", "2001:db8::6"}},
+		{"step", "2001:db8::/125", false, 1, 3, []string{"2001:db8::1", "2001:db8::4", "2001:db8::7"}},
+		{"offset past end", "2001:db8::/126", false, 4, 1, nil},
+		{"/127 usable", "2001:db8::/127", true, 0, 0, []string{"2001:db8::", "2001:db8::1"}},
+		{"/128 usable", "2001:db8::5/128", true, 0, 0, []string{"2001:db8::5"}},
+		{"step past end of space", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffc/126", false, 1, 1 << 63, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffd"}},
+		{"offset past end of space", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ff00/120", false, 1 << 63, 1, nil},
+		{"last address of space", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127", false, 1, 1, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNetwork(tt.cidr)
+			if err != nil {
+				t.Fatalf(`NewNetwork(%s) returned error: %s`, tt.cidr, err)
+			}
+			got := slices.Collect(n.Hosts(tt.usableOnly, tt.offset, tt.step))
+			if want := addrs(t, tt.want...); !slices.Equal(got, want) {
+				t.Errorf(`Hosts(%t, %d, %d) of %s = %v, want %v`, tt.usableOnly, tt.offset, tt.step, tt.cidr, got, want)
+			}
+		})
+	}
+}
+
+// A /64 has too many addresses to collect, so breaking early must stop the iterator.
+func TestHostsBreak(t *testing.T) {
+	// arrange
+	n, err := NewNetwork("2001:db8::/32")
+	if err != nil {
+		t.Fatalf(`NewNetwork returned error: %s`, err)
+	}
+
+	// act
+	var got []netip.Addr
+	for a := range n.Hosts(true, 0, 1) {
+		got = append(got, a)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	// assert
+	if want := addrs(t, "2001:db8::1", "2001:db8::2"); !slices.Equal(got, want) {
+		t.Errorf(`Hosts = %v, want %v`, got, want)
+	}
+}
+
+// For /40, /48 and /56 the IPv4 address straddles the u-octet (bits 64-71),
+// which must be skipped both ways, so every length of RFC 6052 is round-tripped.
+func TestEmbedExtract(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want string // the embedded address
+	}{
+		{"2001:db8::/32", "2001:db8:c000:221::"},
+		{"2001:db8:100::/40", "2001:db8:1c0:2:21::"},
+		{"2001:db8:122::/48", "2001:db8:122:c000:2:2100::"},
+		{"2001:db8:122:300::/56", "2001:db8:122:3c0:0:221::"},
+		{"2001:db8:122:344::/64", "2001:db8:122:344:c0:2:2100:0"},
+		{"64:ff9b::/96", "64:ff9b::c000:221"},
+	}
+	v4 := "192.0.2.33"
+	for _, tt := range tests {
+		t.Run(tt.cidr, func(t *testing.T) {
+			n, err := NewNetwork(tt.cidr)
+			if err != nil {
+				t.Fatalf(`NewNetwork(%s) returned error: %s`, tt.cidr, err)
+			}
+			embedded, err := n.Embed(v4)
+			if err != nil {
+				t.Fatalf(`Embed(%s) returned error: %s`, v4, err)
+			}
+			extracted, err := n.Extract(embedded.String())
+			if embedded.String() != tt.want {
+				t.Errorf(`Embed(%s) = %s, want %s`, v4, embedded, tt.want)
+			}
+			if err != nil {
+				t.Fatalf(`Extract(%s) returned error: %s`, embedded, err)
+			}
+			if extracted.String() != v4 {
+				t.Errorf(`Extract(%s) = %s, want %s`, embedded, extracted, v4)
+			}
+		})
+	}
+}
+
+// A set u-octet, an address outside the prefix, a length RFC 6052 does not define
+// and a v4 address are all refused.
+func TestExtractInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cidr string
+		addr string
+	}{
+		{"non-zero u-octet", "2001:db8:122:344::/64", "2001:db8:122:344:1c0:2:2100:0"},
+		{"outside prefix", "2001:db8::/32", "2001:db9:c000:221::"},
+		{"invalid prefix length", "2001:db8::/33", "2001:db8:c000:221::"},
+		{"v4 address", "64:ff9b::/96", "192.0.2.33"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := NewNetwork(tt.cidr)
+			if err != nil {
+				t.Fatalf(`NewNetwork(%s) returned error: %s`, tt.cidr, err)
+			}
+			_, err = n.Extract(tt.addr)
+			if err == nil {
+				t.Errorf(`Extract(%s) in %s returned no error`, tt.addr, tt.cidr)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package v6
+
+import (
+	"math/big"
+	"net/netip"
+)
+
+// RangeToPrefixes returns the smallest list of IPv6 prefixes that covers
+// exactly the addresses from first to last, inclusive, sorted by address.
+// It returns nil if first is greater than last.
+func RangeToPrefixes(first, last netip.Addr) []netip.Prefix {
+	lo, hi := addrToBig(first), addrToBig(last)
+	one := big.NewInt(1)
+
+	var out []netip.Prefix
+	for lo.Cmp(hi) <= 0 {
+		// Largest block that starts at lo (alignment) and does not run past hi.
+		hostBits := 128
+		if lo.Sign() != 0 {
+			hostBits = int(lo.TrailingZeroBits())
+		}
+		remaining := new(big.Int).Sub(hi, lo)
+		remaining.Add(remaining, one)
+		size := new(big.Int).Lsh(one, uint(hostBits))
+		for size.Cmp(remaining) > 0 {
+			hostBits--
+			size.Rsh(size, 1)
+		}
+		out = append(out, netip.PrefixFrom(bigToAddr16(lo), 128-hostBits))
+		lo = new(big.Int).Add(lo, size)
+	}
+	return out
+}
@@ -0,0 +1,264 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"net/netip"
+	"slices"
+
+	"github.com/jokarl/go-learning-projects/cidr/math"
+)
+
+// MaxSubnets is the most subnets a divide or a single subnet request may list.
+// Every subnet is held in memory, so larger counts are rejected rather than exhausting it.
+const MaxSubnets = 1 << 20
+
+// VLSMRequest asks for a subnet with room for a number of hosts.
+type VLSMRequest struct {
+	Name  string
+	Hosts int
+}
+
+// VLSMAllocation is the subnet allocated for a request.
+type VLSMAllocation struct {
+	VLSMRequest
+	Prefix netip.Prefix
+}
+
+// UsableHosts returns the number of host addresses in the subnet,
+// which excludes the network address and the last address.
+func (a VLSMAllocation) UsableHosts() *big.Int {
+	return usableHosts(a.Prefix)
+}
+
+// Wasted returns the number of usable addresses beyond the requested hosts.
+func (a VLSMAllocation) Wasted() *big.Int {
+	return new(big.Int).Sub(a.UsableHosts(), big.NewInt(int64(a.Hosts)))
+}
+
+// SubnetRequest asks for Count subnets that are Bits long, allocated together in one block.
+// This is how v6 space is planned, where host counts are meaningless.
+type SubnetRequest struct {
+	Name  string
+	Count int
+	Bits  int
+}
+
+// String returns the request as "12x/64".
+func (r SubnetRequest) String() string {
+	return fmt.Sprintf("%dx/%d", r.Count, r.Bits)
+}
+
+// SubnetAllocation is the block allocated for a subnet request.
+type SubnetAllocation struct {
+	SubnetRequest
+	Prefix netip.Prefix
+}
+
+// Subnets returns the requested subnets, from the start of the block.
+// VLSMSubnets only allocates requests of at most MaxSubnets subnets.
+func (a SubnetAllocation) Subnets() []netip.Prefix {
+	addrBits := a.Prefix.Addr().BitLen()
+	base := addrBig(a.Prefix.Addr())
+	step := new(big.Int).Lsh(big.NewInt(1), uint(addrBits-a.Bits))
+	out := make([]netip.Prefix, a.Count)
+	for i := range out {
+		out[i] = netip.PrefixFrom(bigAddr(base, addrBits), a.Bits)
+		base.Add(base, step)
+	}
+	return out
+}
+
+// Slash64s returns the number of /64 networks in the block, which is 0 for blocks longer than /64.
+func (a SubnetAllocation) Slash64s() *big.Int {
+	if a.Prefix.Bits() > 64 {
+		return new(big.Int)
+	}
+	return new(big.Int).Lsh(big.NewInt(1), uint(64-a.Prefix.Bits()))
+}
+
+// VLSMOptions changes how VLSM places requests.
+type VLSMOptions struct {
+	// Reserved blocks are already in use and are never allocated.
+	Reserved []netip.Prefix
+	// InputOrder places and returns the requests in the order given,
+	// instead of placing the largest first and returning them by address.
+	InputOrder bool
+	// Nibble rounds every allocation up to a multiple of 4 bits, so each one starts and ends
+	// on a hex digit and can be delegated as its own reverse DNS zone. It only applies to v6.
+	Nibble bool
+}
+
+// VLSM allocates a subnet for every request inside p, using the best-fit strategy:
+// each request gets the smallest free block that fits, so large blocks stay available.
+// Each subnet reserves its network and last address, so a request for n hosts gets
+// a block of at least n+2 addresses. The leftover space excludes reserved blocks.
+func VLSM(p netip.Prefix, reqs []VLSMRequest, opts VLSMOptions) (allocated []VLSMAllocation, leftover []netip.Prefix, err error) {
+	addrBits := p.Addr().BitLen()
+
+	bits := make([]int, len(reqs))
+	for i, r := range reqs {
+		if bits[i], err = HostBits(r.Hosts, addrBits); err != nil {
+			return nil, nil, requestError(r.Name, "%w", err)
+		}
+	}
+
+	blocks, leftover, failed, err := Place(p, bits, opts)
+	if err != nil {
+		if failed >= 0 {
+			return nil, nil, requestError(reqs[failed].Name, "insufficient address space for %d hosts", reqs[failed].Hosts)
+		}
+		return nil, nil, err
+	}
+
+	allocated = make([]VLSMAllocation, len(reqs))
+	for i, r := range reqs {
+		allocated[i] = VLSMAllocation{VLSMRequest: r, Prefix: blocks[i]}
+	}
+	if !opts.InputOrder {
+		slices.SortFunc(allocated, func(a, b VLSMAllocation) int {
+			return a.Prefix.Addr().Compare(b.Prefix.Addr())
+		})
+	}
+	return allocated, leftover, nil
+}
+
+// HostBits returns the length of the smallest prefix of an addrBits long address
+// that holds hosts addresses besides its network and last address, as VLSM allocates it.
+func HostBits(hosts, addrBits int) (int, error) {
+	if hosts <= 0 {
+		return 0, fmt.Errorf("host count must be > 0 (got %d)", hosts)
+	}
+	// Checked before adding the network and last address, so hosts+2 cannot overflow.
+	// v6 counts are capped at 2^62 addresses, which is still more than anyone can use.
+	if limit := 1<<min(addrBits, 62) - 2; hosts > limit {
+		return 0, fmt.Errorf("%d hosts do not fit in any prefix, the most is %d", hosts, limit)
+	}
+	// bits.Len64 is exact where math.NextPow2 would round counts above 2^53.
+	return addrBits - bits.Len64(uint64(hosts+1)), nil
+}
+
+// VLSMSubnets allocates a block for every request inside p, like VLSM.
+// Each block is just large enough to hold the requested number of subnets,
+// so a request for 12 /64s gets a /60 of which the first 12 /64s are used.
+func VLSMSubnets(p netip.Prefix, reqs []SubnetRequest, opts VLSMOptions) (allocated []SubnetAllocation, leftover []netip.Prefix, err error) {
+	addrBits := p.Addr().BitLen()
+
+	lengths := make([]int, len(reqs))
+	for i, r := range reqs {
+		if r.Count <= 0 {
+			return nil, nil, requestError(r.Name, "subnet count must be > 0 (got %d)", r.Count)
+		}
+		if r.Count > MaxSubnets {
+			return nil, nil, requestError(r.Name, "subnet count must be at most %d (got %d)", MaxSubnets, r.Count)
+		}
+		if r.Bits < 0 || r.Bits > addrBits {
+			return nil, nil, requestError(r.Name, "invalid prefix length /%d", r.Bits)
+		}
+		lengths[i] = r.Bits - bits.Len64(uint64(r.Count-1))
+		if lengths[i] < 0 {
+			return nil, nil, requestError(r.Name, "%s does not fit in any prefix", r)
+		}
+	}
+
+	blocks, leftover, failed, err := Place(p, lengths, opts)
+	if err != nil {
+		if failed >= 0 {
+			return nil, nil, requestError(reqs[failed].Name, "insufficient address space for %s", reqs[failed])
+		}
+		return nil, nil, err
+	}
+
+	allocated = make([]SubnetAllocation, len(reqs))
+	for i, r := range reqs {
+		allocated[i] = SubnetAllocation{SubnetRequest: r, Prefix: blocks[i]}
+	}
+	if !opts.InputOrder {
+		slices.SortFunc(allocated, func(a, b SubnetAllocation) int {
+			return a.Prefix.Addr().Compare(b.Prefix.Addr())
+		})
+	}
+	return allocated, leftover, nil
+}
+
+// DivideNibble divides a v6 prefix into c subnets whose length is a multiple of 4 bits.
+// The length is rounded up, so some subnets at the end of p may be left unused.
+func DivideNibble(p netip.Prefix, c int) ([]netip.Prefix, error) {
+	if !p.Addr().Is6() {
+		return nil, fmt.Errorf("nibble alignment only applies to IPv6 networks")
+	}
+	if c <= 0 {
+		return nil, fmt.Errorf("count must be > 0")
+	}
+	if c > MaxSubnets {
+		return nil, fmt.Errorf("count must be at most %d", MaxSubnets)
+	}
+	bits := nibbleUp(p.Bits() + math.NextPow2(c))
+	if bits > 128 {
+		return nil, fmt.Errorf("prefix would exceed %d bits", 128)
+	}
+	a := SubnetAllocation{SubnetRequest: SubnetRequest{Count: c, Bits: bits}, Prefix: p.Masked()}
+	return a.Subnets(), nil
+}
+
+// Place allocates a block of each length in bits from p with the best-fit strategy,
+// placing the largest first unless opts.InputOrder is set. Blocks are returned in the
+// order of bits. If a block does not fit, failed is its index and leftover is the
+// space that was still free; otherwise failed is -1.
+func Place(p netip.Prefix, bits []int, opts VLSMOptions) (blocks, leftover []netip.Prefix, failed int, err error) {
+	p = p.Masked()
+	if opts.Nibble {
+		if !p.Addr().Is6() {
+			return nil, nil, -1, fmt.Errorf("nibble alignment only applies to IPv6 networks")
+		}
+		bits = slices.Clone(bits)
+		for i := range bits {
+			bits[i] = nibbleDown(bits[i])
+		}
+	}
+
+	order := make([]int, len(bits))
+	for i := range order {
+		order[i] = i
+	}
+	if !opts.InputOrder {
+		// Place the largest requests first so small ones do not fragment the space.
+		slices.SortStableFunc(order, func(a, b int) int { return bits[a] - bits[b] })
+	}
+
+	free := Exclude([]netip.Prefix{p}, opts.Reserved)
+	blocks = make([]netip.Prefix, len(bits))
+	for _, i := range order {
+		var rest []netip.Prefix
+		if blocks[i], rest, err = Allocate(p, free, bits[i]); err != nil {
+			return nil, Aggregate(free), i, err
+		}
+		free = rest
+	}
+	return blocks, Aggregate(free), -1, nil
+}
+
+// requestError returns an error prefixed with the name of the request, if it has one.
+func requestError(name, format string, args ...any) error {
+	err := fmt.Errorf(format, args...)
+	if name != "" {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return err
+}
+
+// nibbleDown and nibbleUp round a prefix length to a multiple of 4 bits.
+func nibbleDown(bits int) int { return bits &^ 3 }
+func nibbleUp(bits int) int   { return (bits + 3) &^ 3 }
+
+// usableHosts counts the addresses between the network address and the last address.
+// /31, /32, /127 and /128 networks have no reserved addresses.
+func usableHosts(p netip.Prefix) *big.Int {
+	hostBits := p.Addr().BitLen() - p.Bits()
+	n := new(big.Int).Lsh(big.NewInt(1), uint(hostBits))
+	if hostBits > 1 {
+		n.Sub(n, big.NewInt(2))
+	}
+	return n
+}
@@ -0,0 +1,170 @@
+package network
+
+import (
+	"math"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// The same requests are placed with and without reserved blocks and input order,
+// so the test shows how each option moves the allocations and changes the waste.
+func TestVLSM(t *testing.T) {
+	reqs := []VLSMRequest{{"mgmt", 10}, {"web", 120}, {"db", 30}}
+	tests := []struct {
+		name     string
+		opts     VLSMOptions
+		want     []string // name=prefix, in the returned order
+		leftover []string
+	}{
+		{"largest first", VLSMOptions{}, []string{"web=10.0.0.0/25", "db=10.0.0.128/27", "mgmt=10.0.0.160/28"}, []string{"10.0.0.176/28", "10.0.0.192/26", "10.0.1.0/24"}},
+		{"input order", VLSMOptions{InputOrder: true}, []string{"mgmt=10.0.0.0/28", "web=10.0.0.128/25", "db=10.0.0.32/27"}, []string{"10.0.0.16/28", "10.0.0.64/26", "10.0.1.0/24"}},
+		{"reserved", VLSMOptions{Reserved: prefixes(t, "10.0.0.0/24")}, []string{"web=10.0.1.0/25", "db=10.0.1.128/27", "mgmt=10.0.1.160/28"}, []string{"10.0.1.176/28", "10.0.1.192/26"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, leftover, err := VLSM(netip.MustParsePrefix("10.0.0.0/23"), reqs, tt.opts)
+			if err != nil {
+				t.Fatalf(`VLSM returned error: %s`, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf(`VLSM returned %d allocations, want %d`, len(got), len(tt.want))
+			}
+			for i, a := range got {
+				if s := a.Name + "=" + a.Prefix.String(); s != tt.want[i] {
+					t.Errorf(`allocation %d = %s, want %s`, i, s, tt.want[i])
+				}
+			}
+			if want := prefixes(t, tt.leftover...); !slices.Equal(leftover, want) {
+				t.Errorf(`leftover = %v, want %v`, leftover, want)
+			}
+		})
+	}
+
+	t.Run("waste", func(t *testing.T) {
+		a := VLSMAllocation{VLSMRequest: VLSMRequest{Hosts: 120}, Prefix: netip.MustParsePrefix("10.0.0.0/25")}
+		if a.UsableHosts().Int64() != 126 || a.Wasted().Int64() != 6 {
+			t.Errorf(`usable, wasted = %s, %s, want 126, 6`, a.UsableHosts(), a.Wasted())
+		}
+	})
+
+	t.Run("does not fit", func(t *testing.T) {
+		_, _, err := VLSM(netip.MustParsePrefix("10.0.0.0/24"), []VLSMRequest{{"web", 300}}, VLSMOptions{})
+		if err == nil || err.Error() != "web: insufficient address space for 300 hosts" {
+			t.Errorf(`VLSM error = %v, want the request name and host count`, err)
+		}
+	})
+}
+
+// Nibble alignment rounds every block out to a multiple of 4 bits, so three /56s
+// take a /52 instead of a /54, and it is refused for v4 networks.
+func TestVLSMSubnets(t *testing.T) {
+	reqs := []SubnetRequest{{"lan", 12, 64}, {"wan", 3, 56}, {"dmz", 1, 62}}
+	tests := []struct {
+		name     string
+		nibble   bool
+		want     []string // name=block
+		slash64s []int64
+	}{
+		{"exact", false, []string{"wan=2001:db8::/54", "lan=2001:db8:0:400::/60", "dmz=2001:db8:0:410::/62"}, []int64{1024, 16, 4}},
+		{"nibble", true, []string{"wan=2001:db8::/52", "lan=2001:db8:0:1000::/60", "dmz=2001:db8:0:1010::/60"}, []int64{4096, 16, 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := VLSMSubnets(netip.MustParsePrefix("2001:db8::/48"), reqs, VLSMOptions{Nibble: tt.nibble})
+			if err != nil {
+				t.Fatalf(`VLSMSubnets returned error: %s`, err)
+			}
+			for i, a := range got {
+				if s := a.Name + "=" + a.Prefix.String(); s != tt.want[i] {
+					t.Errorf(`allocation %d = %s, want %s`, i, s, tt.want[i])
+				}
+				if n := a.Slash64s().Int64(); n != tt.slash64s[i] {
+					t.Errorf(`%s holds %d /64s, want %d`, a.Prefix, n, tt.slash64s[i])
+				}
+			}
+			if subnets := got[1].Subnets(); len(subnets) != 12 || subnets[11].Bits() != 64 || !got[1].Prefix.Contains(subnets[11].Addr()) {
+				t.Errorf(`lan subnets = %v, want 12 /64s inside %s`, subnets, got[1].Prefix)
+			}
+		})
+	}
+
+	t.Run("nibble on v4", func(t *testing.T) {
+		_, _, err := VLSMSubnets(netip.MustParsePrefix("10.0.0.0/16"), []SubnetRequest{{"", 4, 24}}, VLSMOptions{Nibble: true})
+		if err == nil {
+			t.Errorf(`VLSMSubnets returned no error for nibble alignment of a v4 network`)
+		}
+	})
+}
+
+// Three subnets need two bits, which nibble alignment rounds up to four.
+func TestDivideNibble(t *testing.T) {
+	// act
+	got, err := DivideNibble(netip.MustParsePrefix("2001:db8::/48"), 3)
+
+	// assert
+	if err != nil {
+		t.Fatalf(`DivideNibble returned error: %s`, err)
+	}
+	want := prefixes(t, "2001:db8::/52", "2001:db8:0:1000::/52", "2001:db8:0:2000::/52")
+	if !slices.Equal(got, want) {
+		t.Errorf(`DivideNibble = %v, want %v`, got, want)
+	}
+}
+
+// Every subnet is held in memory, so counts above MaxSubnets are refused
+// up front, while MaxSubnets itself still works.
+func TestMaxSubnets(t *testing.T) {
+	// arrange
+	p := netip.MustParsePrefix("2001:db8::/32")
+	reqs := []SubnetRequest{{"lan", 1 << 40, 72}}
+
+	// act
+	_, _, vlsmErr := VLSMSubnets(p, reqs, VLSMOptions{})
+	_, divideErr := DivideNibble(p, 1<<40)
+
+	// assert
+	if want := "lan: subnet count must be at most 1048576 (got 1099511627776)"; vlsmErr == nil || vlsmErr.Error() != want {
+		t.Errorf(`VLSMSubnets error = %v, want %q`, vlsmErr, want)
+	}
+	if divideErr == nil {
+		t.Errorf(`DivideNibble(%s, 1<<40) returned no error`, p)
+	}
+	if _, err := DivideNibble(p, MaxSubnets); err != nil {
+		t.Errorf(`DivideNibble(%s, MaxSubnets) returned error: %s`, p, err)
+	}
+}
+
+// Host counts near the limits: the largest v4 count, counts around 2^53 where
+// a float log2 would round, and counts so large that adding two would overflow.
+func TestHostBits(t *testing.T) {
+	tests := []struct {
+		hosts    int
+		addrBits int
+		want     int // -1 for an error
+	}{
+		{2, 32, 30},
+		{2, 128, 126},
+		{120, 32, 25},
+		{1<<32 - 2, 32, 0},
+		{1<<32 - 1, 32, -1},
+		{1<<53 - 2, 128, 75},
+		{1<<53 - 1, 128, 74},
+		{1<<60 - 1, 128, 67},
+		{0, 32, -1},
+		{math.MaxInt, 32, -1},
+		{math.MaxInt, 128, -1},
+	}
+	for _, tt := range tests {
+		got, err := HostBits(tt.hosts, tt.addrBits)
+		if tt.want < 0 {
+			if err == nil {
+				t.Errorf(`HostBits(%d, %d) = %d, want an error`, tt.hosts, tt.addrBits, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf(`HostBits(%d, %d) = %d, %v, want %d`, tt.hosts, tt.addrBits, got, err, tt.want)
+		}
+	}
+}
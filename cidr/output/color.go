@@ -1,5 +1,7 @@
 package output
 
+import "os"
+
 type color int
 
 const (
@@ -8,16 +10,43 @@ const (
 	Blue
 	Green
 	Yellow
+	Magenta
+	Cyan
 )
 
 var colorValue = map[color]string{
-	Reset:  "\033[0m",
-	Red:    "\033[31m",
-	Blue:   "\033[34m",
-	Green:  "\033[32m",
-	Yellow: "\033[33m",
+	Reset:   "\033[0m",
+	Red:     "\033[31m",
+	Blue:    "\033[34m",
+	Green:   "\033[32m",
+	Yellow:  "\033[33m",
+	Magenta: "\033[35m",
+	Cyan:    "\033[36m",
+}
+
+// colorEnabled is off when NO_COLOR is set, see https://no-color.org,
+// and when stdout is not a terminal, so escapes are not written to pipes and files.
+var colorEnabled = os.Getenv("NO_COLOR") == "" && IsTerminal(os.Stdout)
+
+// IsTerminal reports whether f is a terminal rather than a pipe or a file.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// SetColor turns colours on or off. When off, colours print as empty strings.
+func SetColor(on bool) {
+	colorEnabled = on
+}
+
+// ColorEnabled reports whether colours are printed.
+func ColorEnabled() bool {
+	return colorEnabled
 }
 
 func (c color) String() string {
+	if !colorEnabled {
+		return ""
+	}
 	return colorValue[c]
 }
@@ -0,0 +1,34 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Colours are only written to terminals, never to files or pipes.
+func TestIsTerminal(t *testing.T) {
+	// arrange
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	// act
+	file, pipe := IsTerminal(f), IsTerminal(w)
+
+	// assert
+	if file {
+		t.Errorf(`IsTerminal(file) = true, want false`)
+	}
+	if pipe {
+		t.Errorf(`IsTerminal(pipe) = true, want false`)
+	}
+}
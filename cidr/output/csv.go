@@ -0,0 +1,123 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// CSVFormatter writes a header row and one row per element of a slice of structs,
+// or a single row for a struct. Columns are named after the json field names,
+// so they match the keys of the json output.
+type CSVFormatter struct{}
+
+func newCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{}
+}
+
+func (cf *CSVFormatter) Print(data any) error {
+	return cf.Fprint(os.Stdout, data)
+}
+
+func (cf *CSVFormatter) Fprint(w io.Writer, data any) error {
+	rows, err := structRows("csv", data)
+	if err != nil {
+		return err
+	}
+	head, ok := headerRow(data, rows)
+	if !ok {
+		return nil
+	}
+
+	cw := csv.NewWriter(w)
+	fields := collectCSVFields(head)
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.label
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		fields := collectCSVFields(row)
+		record := make([]string, len(fields))
+		for i, f := range fields {
+			record[i] = f.value
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// structRows returns the structs to print as rows:
+// the elements of a slice, or data itself if it is a struct.
+func structRows(formatter string, data any) ([]reflect.Value, error) {
+	v := reflect.ValueOf(data)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("%s formatter: nil data", formatter)
+	}
+	v = deref(v)
+
+	var rows []reflect.Value
+	switch v.Kind() {
+	case reflect.Struct:
+		rows = []reflect.Value{v}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			rows = append(rows, deref(v.Index(i)))
+		}
+	default:
+		return nil, fmt.Errorf("%s formatter: unsupported kind %s", formatter, v.Kind())
+	}
+	for _, r := range rows {
+		if r.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("%s formatter: element must be struct, got %s", formatter, r.Kind())
+		}
+	}
+	return rows, nil
+}
+
+// headerRow returns a struct to take the column names from. Without rows, that is the
+// zero value of the slice's element type, so the header is printed even for no rows.
+// It reports false if the element type is not a struct, as with an empty []any.
+func headerRow(data any, rows []reflect.Value) (reflect.Value, bool) {
+	if len(rows) > 0 {
+		return rows[0], true
+	}
+	t := deref(reflect.ValueOf(data)).Type().Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	return reflect.Zero(t), true
+}
+
+// collectCSVFields returns every exported field labeled with its json name.
+// Nothing is omitted, so all rows have the same columns.
+func collectCSVFields(v reflect.Value) []tabField {
+	t := v.Type()
+	out := make([]tabField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" { // unexported
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		out = append(out, tabField{label: name, value: stringifyField(v.Field(i))})
+	}
+	return out
+}
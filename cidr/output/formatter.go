@@ -15,8 +15,12 @@ type Formatter interface {
 const DefaultFormat = "tab"
 
 var availableFormats = map[string]Formatter{
-	"tab":  newTabFormatter(),
-	"json": newJSONFormatter(),
+	"tab":      newTabFormatter(),
+	"json":     newJSONFormatter(),
+	"yaml":     newYAMLFormatter(),
+	"csv":      newCSVFormatter(),
+	"markdown": newMarkdownFormatter(),
+	"ndjson":   newNDJSONFormatter(),
 }
 
 func Formats() []string {
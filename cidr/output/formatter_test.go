@@ -0,0 +1,63 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+type testRow struct {
+	Name  string `json:"name" tabs:"Name"`
+	Count int    `json:"count" tabs:"Count"`
+	Note  string `json:"note,omitempty" tabs:"Note,omitempty"`
+}
+
+// The same rows in every structured format, with a value that needs escaping
+// in Markdown and one that YAML would otherwise read as a boolean.
+func TestFormatters(t *testing.T) {
+	rows := []testRow{{Name: "a|b", Count: 1, Note: "x"}, {Name: "true", Count: 2}}
+	tests := map[string]string{
+		"yaml":     "- name: a|b\n  count: 1\n  note: x\n- name: \"true\"\n  count: 2\n",
+		"csv":      "name,count,note\na|b,1,x\ntrue,2,\n",
+		"markdown": "| Name | Count | Note |\n| --- | --- | --- |\n| a\\|b | 1 | x |\n| true | 2 |  |\n",
+		"ndjson":   "{\"name\":\"a|b\",\"count\":1,\"note\":\"x\"}\n{\"name\":\"true\",\"count\":2}\n",
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := GetFormatter(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := f.Fprint(&b, rows); err != nil {
+				t.Fatalf(`Fprint returned error: %s`, err)
+			}
+			if b.String() != want {
+				t.Errorf("Fprint printed\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
+
+// Without rows, CSV and Markdown still print their header so scripts can tell
+// an empty result from no output.
+func TestFormattersEmpty(t *testing.T) {
+	tests := map[string]string{
+		"csv":      "name,count,note\n",
+		"markdown": "| Name | Count | Note |\n| --- | --- | --- |\n",
+	}
+	for name, want := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := GetFormatter(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := f.Fprint(&b, []*testRow{}); err != nil {
+				t.Fatalf(`Fprint returned error: %s`, err)
+			}
+			if b.String() != want {
+				t.Errorf("Fprint printed\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// MarkdownFormatter writes a GitHub flavored Markdown table, using the same labels as the tab formatter.
+// A slice of structs becomes one row per element; a single struct becomes a "Field | Value" table.
+type MarkdownFormatter struct{}
+
+func newMarkdownFormatter() *MarkdownFormatter {
+	return &MarkdownFormatter{}
+}
+
+func (mf *MarkdownFormatter) Print(data any) error {
+	return mf.Fprint(os.Stdout, data)
+}
+
+func (mf *MarkdownFormatter) Fprint(w io.Writer, data any) error {
+	rows, err := structRows("markdown", data)
+	if err != nil {
+		return err
+	}
+	head, ok := headerRow(data, rows)
+	if !ok {
+		return nil
+	}
+
+	var b strings.Builder
+	if deref(reflect.ValueOf(data)).Kind() == reflect.Struct {
+		writeMarkdownRow(&b, []string{"Field", "Value"})
+		writeMarkdownRow(&b, []string{"---", "---"})
+		for _, f := range collectTabFields(head) {
+			writeMarkdownRow(&b, []string{f.label, f.value})
+		}
+	} else {
+		fields := collectFields(head, false)
+		header := make([]string, len(fields))
+		sep := make([]string, len(fields))
+		for i, f := range fields {
+			header[i], sep[i] = f.label, "---"
+		}
+		writeMarkdownRow(&b, header)
+		writeMarkdownRow(&b, sep)
+		for _, row := range rows {
+			fields := collectFields(row, false)
+			cells := make([]string, len(fields))
+			for i, f := range fields {
+				cells[i] = f.value
+			}
+			writeMarkdownRow(&b, cells)
+		}
+	}
+	_, err = fmt.Fprint(w, b.String())
+	return err
+}
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, c := range cells {
+		b.WriteString(" ")
+		b.WriteString(markdownEscaper.Replace(c))
+		b.WriteString(" |")
+	}
+	b.WriteString("\n")
+}
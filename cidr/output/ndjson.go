@@ -0,0 +1,34 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"reflect"
+)
+
+// NDJSONFormatter writes newline-delimited JSON: one compact object per line
+// for every element of a slice, or a single line for anything else.
+type NDJSONFormatter struct{}
+
+func newNDJSONFormatter() *NDJSONFormatter {
+	return &NDJSONFormatter{}
+}
+
+func (nf *NDJSONFormatter) Print(data any) error {
+	return nf.Fprint(os.Stdout, data)
+}
+
+func (nf *NDJSONFormatter) Fprint(w io.Writer, data any) error {
+	encoder := json.NewEncoder(w)
+	v := deref(reflect.ValueOf(data))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return encoder.Encode(data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := encoder.Encode(v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
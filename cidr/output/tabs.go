@@ -128,7 +128,8 @@ func (tf *TabFormatter) Fprint(w io.Writer, data any) error {
 		if row0.Kind() != reflect.Struct {
 			return fmt.Errorf("tab formatter: slice element must be struct, got %s", row0.Kind())
 		}
-		fields := collectTabFields(row0)
+		// Zero values are never omitted in tables, so every row has the same columns.
+		fields := collectFields(row0, false)
 
 		// Header
 		for i, f := range fields {
@@ -142,7 +143,7 @@ func (tf *TabFormatter) Fprint(w io.Writer, data any) error {
 		// Rows
 		for i := 0; i < v.Len(); i++ {
 			elem := deref(v.Index(i))
-			fields := collectTabFields(elem)
+			fields := collectFields(elem, false)
 			for j, f := range fields {
 				if j > 0 {
 					tw.tab()
@@ -166,6 +167,12 @@ type tabField struct {
 }
 
 func collectTabFields(v reflect.Value) []tabField {
+	return collectFields(v, true)
+}
+
+// collectFields returns the labeled values of the exported fields of a struct.
+// If honorOmit is false, fields tagged omitempty are kept even when they are zero.
+func collectFields(v reflect.Value, honorOmit bool) []tabField {
 	t := v.Type()
 	out := make([]tabField, 0, t.NumField())
 
@@ -182,7 +189,7 @@ func collectTabFields(v reflect.Value) []tabField {
 		}
 
 		fv := v.Field(i)
-		if tag.omit && isZeroValue(fv) {
+		if honorOmit && tag.omit && isZeroValue(fv) {
 			continue
 		}
 
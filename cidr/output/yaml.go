@@ -0,0 +1,49 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+type YAMLFormatter struct{}
+
+func newYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+func (yf *YAMLFormatter) Print(data any) error {
+	return yf.Fprint(os.Stdout, data)
+}
+
+// Fprint writes data as YAML. The data is encoded as JSON first, so the YAML
+// uses the same field names, key order and value formats as the json output.
+func (yf *YAMLFormatter) Fprint(w io.Writer, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+	// JSON is flow-style YAML with quoted strings; reset the styles
+	// so the document is written in block style.
+	clearStyle(&doc)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+func clearStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
@@ -0,0 +1,156 @@
+// Package plan allocates hierarchical address plans described in YAML or JSON files.
+package plan
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"gopkg.in/yaml.v3"
+)
+
+// Block is a named block of a plan. The top-level block has a CIDR;
+// every other block asks for either a number of hosts or a prefix length,
+// and may contain blocks of its own.
+type Block struct {
+	Name   string  `yaml:"name"`
+	CIDR   string  `yaml:"cidr,omitempty"`
+	Hosts  int     `yaml:"hosts,omitempty"`
+	Prefix string  `yaml:"prefix,omitempty"` // "20" or "/20"
+	Blocks []Block `yaml:"blocks,omitempty"`
+}
+
+// Node is an allocated block together with the free space left inside it.
+type Node struct {
+	Name     string
+	Path     string // names from the top-level block down, joined by "/"
+	Hosts    int    // requested hosts, 0 if a prefix length was requested
+	Prefix   netip.Prefix
+	Children []*Node
+	Free     []netip.Prefix
+}
+
+// Load reads a plan file. YAML is a superset of JSON, so both are accepted.
+// Unknown keys are rejected to catch typos such as "host" for "hosts".
+func Load(path string) (Block, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Block{}, err
+	}
+	return Parse(b)
+}
+
+// Parse decodes a plan from YAML or JSON.
+func Parse(data []byte) (Block, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	var root Block
+	if err := dec.Decode(&root); err != nil {
+		if errors.Is(err, io.EOF) {
+			return Block{}, fmt.Errorf("empty plan")
+		}
+		return Block{}, fmt.Errorf("invalid plan: %w", err)
+	}
+	return root, nil
+}
+
+// Apply allocates every block of the plan inside its parent, depth first.
+// Within a parent, blocks are placed largest first with the VLSM best-fit strategy,
+// so small blocks do not fragment the space large ones need.
+// The children of each node keep the order of the plan.
+func Apply(root Block) (*Node, error) {
+	if root.CIDR == "" {
+		return nil, fmt.Errorf("block %q: the top-level block needs a cidr", root.Name)
+	}
+	if root.Hosts != 0 || root.Prefix != "" {
+		return nil, fmt.Errorf("block %q: the top-level block takes a cidr, not hosts or a prefix", root.Name)
+	}
+	p, err := network.ParsePrefix(root.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("block %q: %w", root.Name, err)
+	}
+	n := &Node{Name: root.Name, Path: root.Name, Prefix: p.Masked()}
+	return n, allocate(n, root.Blocks)
+}
+
+func allocate(parent *Node, blocks []Block) error {
+	addrBits := parent.Prefix.Addr().BitLen()
+	bits := make([]int, len(blocks))
+	// Names must be unique among siblings, or two blocks would share a path.
+	names := make(map[string]bool, len(blocks))
+	for i, b := range blocks {
+		path := parent.Path + "/" + b.Name
+		if b.Name == "" {
+			return fmt.Errorf("block %d in %q has no name", i+1, parent.Path)
+		}
+		if names[b.Name] {
+			return fmt.Errorf("block %q: another block in %q has the same name", path, parent.Path)
+		}
+		names[b.Name] = true
+		if b.CIDR != "" {
+			return fmt.Errorf("block %q: only the top-level block can have a cidr", path)
+		}
+		var err error
+		if bits[i], err = blockBits(b, addrBits); err != nil {
+			return fmt.Errorf("block %q: %w", path, err)
+		}
+	}
+
+	placed, free, failed, err := network.Place(parent.Prefix, bits, network.VLSMOptions{})
+	if err != nil {
+		if failed < 0 {
+			return err
+		}
+		return fmt.Errorf("block %q needs a /%d, but %s has no free block that large (free: %s)",
+			parent.Path+"/"+blocks[failed].Name, bits[failed], parent.Path, formatPrefixes(free))
+	}
+
+	parent.Children = make([]*Node, len(blocks))
+	for i, b := range blocks {
+		parent.Children[i] = &Node{Name: b.Name, Path: parent.Path + "/" + b.Name, Hosts: b.Hosts, Prefix: placed[i]}
+	}
+	parent.Free = free
+
+	for i, child := range parent.Children {
+		if err := allocate(child, blocks[i].Blocks); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// blockBits returns the prefix length a block asks for.
+// Host counts are rounded up the same way vlsm does.
+func blockBits(b Block, addrBits int) (int, error) {
+	switch {
+	case b.Hosts != 0 && b.Prefix != "":
+		return 0, fmt.Errorf("set either hosts or prefix, not both")
+	case b.Hosts != 0:
+		return network.HostBits(b.Hosts, addrBits)
+	case b.Prefix != "":
+		bits, err := strconv.Atoi(strings.TrimPrefix(b.Prefix, "/"))
+		if err != nil || bits < 0 || bits > addrBits {
+			return 0, fmt.Errorf("invalid prefix %q: expected a prefix length such as /24", b.Prefix)
+		}
+		return bits, nil
+	default:
+		return 0, fmt.Errorf("set either hosts or prefix")
+	}
+}
+
+func formatPrefixes(ps []netip.Prefix) string {
+	if len(ps) == 0 {
+		return "none"
+	}
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = p.String()
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,115 @@
+package plan
+
+import (
+	"strings"
+	"testing"
+)
+
+const testPlan = `
+name: corp
+cidr: 10.0.0.0/16
+blocks:
+  - name: small
+    hosts: 10
+  - name: az-a
+    prefix: /20
+    blocks:
+      - name: web
+        hosts: 200
+      - name: db
+        prefix: 26
+`
+
+// Blocks are placed largest first to waste as little space as possible,
+// but the result lists them in the order of the plan file, with the free space left in az-a.
+func TestApply(t *testing.T) {
+	// arrange
+	root, err := Parse([]byte(testPlan))
+	if err != nil {
+		t.Fatalf(`Parse returned error: %s`, err)
+	}
+
+	// act
+	tree, err := Apply(root)
+	if err != nil {
+		t.Fatalf(`Apply returned error: %s`, err)
+	}
+
+	// assert
+	want := map[string]string{
+		"corp":          "10.0.0.0/16",
+		"corp/small":    "10.0.16.0/28",
+		"corp/az-a":     "10.0.0.0/20",
+		"corp/az-a/web": "10.0.0.0/24",
+		"corp/az-a/db":  "10.0.1.0/26",
+	}
+	var walk func(n *Node)
+	got := map[string]string{}
+	walk = func(n *Node) {
+		got[n.Path] = n.Prefix.String()
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(tree)
+	for path, p := range want {
+		if got[path] != p {
+			t.Errorf(`block %q = %s, want %s`, path, got[path], p)
+		}
+	}
+	if tree.Children[0].Name != "small" {
+		t.Errorf(`first child = %q, want plan order with "small" first`, tree.Children[0].Name)
+	}
+	if len(tree.Children[1].Free) == 0 || tree.Children[1].Free[0].String() != "10.0.1.64/26" {
+		t.Errorf(`free space of az-a = %v, want it to start at 10.0.1.64/26`, tree.Children[1].Free)
+	}
+}
+
+// Errors in a nested plan are only useful with the path of the block that caused them.
+func TestApplyErrors(t *testing.T) {
+	tests := map[string]struct {
+		plan string
+		want string
+	}{
+		"does not fit":   {"name: x\ncidr: 10.0.0.0/24\nblocks:\n  - {name: a, hosts: 200}\n  - {name: b, hosts: 100}\n", `block "x/b" needs a /25`},
+		"both sizes":     {"name: x\ncidr: 10.0.0.0/24\nblocks:\n  - {name: a, hosts: 2, prefix: 30}\n", `block "x/a": set either hosts or prefix, not both`},
+		"no size":        {"name: x\ncidr: 10.0.0.0/24\nblocks:\n  - {name: a}\n", `block "x/a": set either hosts or prefix`},
+		"no cidr":        {"name: x\nprefix: 24\n", `block "x": the top-level block needs a cidr`},
+		"nested cidr":    {"name: x\ncidr: 10.0.0.0/24\nblocks:\n  - {name: a, cidr: 10.0.0.0/25}\n", `only the top-level block can have a cidr`},
+		"duplicate name": {"name: x\ncidr: 10.0.0.0/24\nblocks:\n  - {name: a, prefix: 26}\n  - {name: a, prefix: 26}\n", `block "x/a": another block in "x" has the same name`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			root, err := Parse([]byte(tt.plan))
+			if err == nil {
+				_, err = Apply(root)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf(`Apply error = %v, want it to contain %q`, err, tt.want)
+			}
+		})
+	}
+}
+
+// A v6 block sized by hosts reserves the network and last address too,
+// so 254 hosts need a /120 just like in vlsm.
+func TestApplyV6Hosts(t *testing.T) {
+	// arrange
+	root, err := Parse([]byte("name: x\ncidr: 2001:db8::/64\nblocks:\n  - {name: a, hosts: 2}\n  - {name: b, hosts: 254}\n"))
+	if err != nil {
+		t.Fatalf(`Parse returned error: %s`, err)
+	}
+
+	// act
+	tree, err := Apply(root)
+	if err != nil {
+		t.Fatalf(`Apply returned error: %s`, err)
+	}
+
+	// assert
+	for i, want := range []string{"2001:db8::100/126", "2001:db8::/120"} {
+		if got := tree.Children[i].Prefix.String(); got != want {
+			t.Errorf(`block %q = %s, want %s`, tree.Children[i].Path, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Package registry classifies prefixes against the IANA special-purpose address registries.
+package registry
+
+import (
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+)
+
+//go:embed ipv4.csv ipv6.csv
+var files embed.FS
+
+// Category groups registry entries by what they are used for.
+type Category string
+
+const (
+	Global        Category = "global" // not in any special-purpose block
+	Mixed         Category = "mixed"  // only parts of the prefix are special-purpose
+	ThisNetwork   Category = "this-network"
+	Private       Category = "private"
+	Shared        Category = "shared"
+	Loopback      Category = "loopback"
+	LinkLocal     Category = "link-local"
+	Documentation Category = "documentation"
+	Benchmarking  Category = "benchmarking"
+	Multicast     Category = "multicast"
+	Broadcast     Category = "broadcast"
+	Anycast       Category = "anycast"
+	Translation   Category = "translation"
+	Tunnel        Category = "tunnel"
+	Protocol      Category = "protocol"
+	Reserved      Category = "reserved"
+)
+
+// Entry is one block of a special-purpose registry.
+type Entry struct {
+	Prefix             netip.Prefix `json:"prefix"`
+	Category           Category     `json:"category"`
+	Name               string       `json:"name"`
+	RFC                string       `json:"rfc"`
+	Source             bool         `json:"source"`
+	Destination        bool         `json:"destination"`
+	Forwardable        bool         `json:"forwardable"`
+	GloballyReachable  bool         `json:"globallyReachable"`
+	ReservedByProtocol bool         `json:"reservedByProtocol"`
+}
+
+// entries holds both registries, sorted by address and then from shortest to longest prefix,
+// so every block comes before the blocks nested in it.
+var entries = mustLoad("ipv4.csv", "ipv6.csv")
+
+// Entries returns all registry entries, IPv4 first, with enclosing blocks before nested ones.
+func Entries() []Entry {
+	return slices.Clone(entries)
+}
+
+// Classification is how a prefix relates to the registry.
+type Classification struct {
+	// Covering are the blocks that contain the whole prefix, from least to most specific.
+	Covering []Entry
+	// Partial are the blocks that lie inside the prefix without covering all of it.
+	Partial []Entry
+}
+
+// Classify returns the registry blocks that overlap p. Host bits of p are ignored.
+// Since two prefixes either nest or are disjoint, every overlapping block
+// either covers p or lies inside it.
+func Classify(p netip.Prefix) Classification {
+	p = p.Masked()
+	var c Classification
+	for _, e := range entries {
+		switch {
+		case e.Prefix.Bits() <= p.Bits() && e.Prefix.Contains(p.Addr()):
+			c.Covering = append(c.Covering, e)
+		case e.Prefix.Bits() > p.Bits() && p.Contains(e.Prefix.Addr()):
+			c.Partial = append(c.Partial, e)
+		}
+	}
+	return c
+}
+
+// Entry returns the most specific block covering the whole prefix.
+// Its flags apply to every address in the prefix (RFC 8190 section 2).
+func (c Classification) Entry() (Entry, bool) {
+	if len(c.Covering) == 0 {
+		return Entry{}, false
+	}
+	return c.Covering[len(c.Covering)-1], true
+}
+
+// Category returns the category of the most specific covering block.
+// Prefixes outside all blocks are Global, and prefixes that are only partly
+// special-purpose are Mixed.
+func (c Classification) Category() Category {
+	if e, ok := c.Entry(); ok {
+		return e.Category
+	}
+	if len(c.Partial) > 0 {
+		return Mixed
+	}
+	return Global
+}
+
+func mustLoad(names ...string) []Entry {
+	var all []Entry
+	for _, name := range names {
+		f, err := files.Open(name)
+		if err != nil {
+			panic(err)
+		}
+		es, err := parse(f)
+		f.Close()
+		if err != nil {
+			panic(fmt.Sprintf("registry: %s: %s", name, err))
+		}
+		all = append(all, es...)
+	}
+	slices.SortStableFunc(all, func(a, b Entry) int {
+		if c := a.Prefix.Addr().Compare(b.Prefix.Addr()); c != 0 {
+			return c
+		}
+		return a.Prefix.Bits() - b.Prefix.Bits()
+	})
+	return all
+}
+
+// parse reads a registry in the CSV layout
+// prefix,category,name,rfc,source,destination,forwardable,globally_reachable,reserved_by_protocol
+// with a header row and '#' comments.
+func parse(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.FieldsPerRecord = 9
+
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty registry")
+	}
+
+	out := make([]Entry, 0, len(records)-1)
+	for _, rec := range records[1:] { // skip header
+		p, err := netip.ParsePrefix(rec[0])
+		if err != nil {
+			return nil, err
+		}
+		var flags [5]bool
+		for i := range flags {
+			if flags[i], err = strconv.ParseBool(rec[4+i]); err != nil {
+				return nil, fmt.Errorf("%s: %w", rec[0], err)
+			}
+		}
+		out = append(out, Entry{
+			Prefix:             p,
+			Category:           Category(rec[1]),
+			Name:               rec[2],
+			RFC:                rec[3],
+			Source:             flags[0],
+			Destination:        flags[1],
+			Forwardable:        flags[2],
+			GloballyReachable:  flags[3],
+			ReservedByProtocol: flags[4],
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,42 @@
+package registry
+
+import (
+	"net/netip"
+	"testing"
+)
+
+// The most specific block wins, so 192.0.0.9/32 is anycast rather than part of
+// the block around it, and a prefix that only partly covers special blocks is mixed.
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        string
+		want      Category
+		wantBlock string
+		partial   int
+	}{
+		{"private", "10.20.0.0/16", Private, "10.0.0.0/8", 0},
+		{"shared", "100.64.0.0/10", Shared, "100.64.0.0/10", 0},
+		{"nested block wins", "192.0.0.9/32", Anycast, "192.0.0.9/32", 0},
+		{"global", "8.8.8.0/24", Global, "", 0},
+		{"partial", "198.0.0.0/8", Mixed, "", 2},
+		{"covered with nested", "224.0.0.0/4", Multicast, "224.0.0.0/4", 3},
+		{"ula", "fd00::/8", Private, "fc00::/7", 0},
+		{"v6 documentation", "2001:db8:1::/48", Documentation, "2001:db8::/32", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Classify(netip.MustParsePrefix(tt.in))
+			if got := c.Category(); got != tt.want {
+				t.Errorf(`Classify(%q).Category() = %q, want %q`, tt.in, got, tt.want)
+			}
+			e, ok := c.Entry()
+			if ok != (tt.wantBlock != "") || ok && e.Prefix.String() != tt.wantBlock {
+				t.Errorf(`Classify(%q).Entry() = %s, %t, want %q`, tt.in, e.Prefix, ok, tt.wantBlock)
+			}
+			if len(c.Partial) != tt.partial {
+				t.Errorf(`Classify(%q).Partial = %v, want %d blocks`, tt.in, c.Partial, tt.partial)
+			}
+		})
+	}
+}
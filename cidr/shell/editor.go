@@ -0,0 +1,216 @@
+package shell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// ErrInterrupt is returned by ReadLine when the line is abandoned with Ctrl-C.
+var ErrInterrupt = errors.New("interrupt")
+
+// Editor reads lines with history and tab completion when its input is a terminal,
+// and plain lines otherwise.
+type Editor struct {
+	in  *bufio.Reader
+	fd  uintptr
+	tty bool
+	out io.Writer
+
+	// History holds the lines read so far, oldest first. Up and down walk through it.
+	History []string
+	// Complete returns the candidates for the word before the cursor.
+	// words holds the preceding words of the line, which is empty for the first word.
+	Complete func(words []string, word string) []string
+}
+
+// NewEditor returns an editor reading from in and echoing to out.
+func NewEditor(in io.Reader, out io.Writer) *Editor {
+	e := &Editor{in: bufio.NewReader(in), out: out}
+	if f, ok := in.(*os.File); ok {
+		e.fd = f.Fd()
+		e.tty = isTerminal(e.fd)
+	}
+	return e
+}
+
+// Interactive reports whether lines are edited on a terminal.
+func (e *Editor) Interactive() bool {
+	return e.tty
+}
+
+// ReadLine prints prompt and reads a line. It returns io.EOF at the end of the input
+// or on Ctrl-D on an empty line.
+func (e *Editor) ReadLine(prompt string) (string, error) {
+	if !e.tty {
+		return e.readPlain()
+	}
+	state, err := makeRaw(e.fd)
+	if err != nil {
+		return e.readPlain()
+	}
+	defer restore(e.fd, state)
+
+	line, err := e.edit(prompt)
+	fmt.Fprint(e.out, "\r\n")
+	if err == nil && strings.TrimSpace(line) != "" {
+		e.History = append(e.History, line)
+	}
+	return line, err
+}
+
+func (e *Editor) readPlain() (string, error) {
+	line, err := e.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) != "" {
+		e.History = append(e.History, line)
+	}
+	return line, nil
+}
+
+// edit reads keys until the line is entered, redrawing it after every change.
+func (e *Editor) edit(prompt string) (string, error) {
+	var line []rune
+	pos := 0
+	hist := len(e.History) // len(History) is the line being typed
+	var draft []rune
+
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - pos; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", back)
+		}
+	}
+	recall := func(i int) {
+		if i < 0 || i > len(e.History) {
+			return
+		}
+		if hist == len(e.History) {
+			draft = line
+		}
+		hist = i
+		if i == len(e.History) {
+			line = draft
+		} else {
+			line = []rune(e.History[i])
+		}
+		pos = len(line)
+	}
+	redraw()
+
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C")
+			return "", ErrInterrupt
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = slices.Delete(line, pos, pos+1)
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(line)
+		case 21: // Ctrl-U
+			line, pos = slices.Clone(line[pos:]), 0
+		case 127, 8: // Backspace
+			if pos > 0 {
+				line = slices.Delete(line, pos-1, pos)
+				pos--
+			}
+		case '\t':
+			line, pos = e.complete(line, pos, prompt)
+		case 27: // escape sequence
+			switch e.escape() {
+			case 'A':
+				recall(hist - 1)
+			case 'B':
+				recall(hist + 1)
+			case 'C':
+				pos = min(pos+1, len(line))
+			case 'D':
+				pos = max(pos-1, 0)
+			case 'H':
+				pos = 0
+			case 'F':
+				pos = len(line)
+			}
+		default:
+			if r >= ' ' {
+				line = slices.Insert(line, pos, r)
+				pos++
+			}
+		}
+		redraw()
+	}
+}
+
+// escape reads the rest of an escape sequence and returns its final byte,
+// e.g. 'A' for the up arrow "ESC [ A".
+func (e *Editor) escape() rune {
+	r, _, err := e.in.ReadRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0
+	}
+	for {
+		r, _, err = e.in.ReadRune()
+		if err != nil {
+			return 0
+		}
+		if r >= '@' && r <= '~' {
+			return r
+		}
+	}
+}
+
+// complete completes the word before the cursor. A single candidate is inserted with a
+// trailing space; several are completed to their common prefix, or listed if that adds nothing.
+func (e *Editor) complete(line []rune, pos int, prompt string) ([]rune, int) {
+	if e.Complete == nil {
+		return line, pos
+	}
+	before := string(line[:pos])
+	start := strings.LastIndexAny(before, " \t") + 1
+	word := before[start:]
+	candidates := e.Complete(strings.Fields(before[:start]), word)
+	if len(candidates) == 0 {
+		return line, pos
+	}
+
+	insert := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		insert += " "
+	}
+	if insert == word {
+		fmt.Fprintf(e.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+		return line, pos
+	}
+	completed := []rune(before[:start] + insert)
+	return append(completed, line[pos:]...), len(completed)
+}
+
+func commonPrefix(ss []string) string {
+	p := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, p) {
+			p = p[:len(p)-1]
+		}
+	}
+	return p
+}
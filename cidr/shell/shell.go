@@ -0,0 +1,338 @@
+// Package shell is an interactive mode that keeps a current network,
+// so a network can be split and explored without retyping prefixes.
+package shell
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jokarl/go-learning-projects/cidr/network"
+	"github.com/jokarl/go-learning-projects/cidr/network/types"
+)
+
+// Runner runs a cidr command line, such as ["explain", "10.0.0.0/16"].
+type Runner func(args []string) error
+
+// Command is a cidr command the shell can run.
+type Command struct {
+	Name    string
+	Aliases []string
+	// TakesNetwork commands get the current network as their first argument.
+	TakesNetwork bool
+}
+
+// Session holds the state of a shell: the path of networks selected so far
+// and the subnets each of them was last split into.
+type Session struct {
+	path     []types.Network
+	splits   [][]netip.Prefix
+	commands []Command
+	run      Runner
+	out      io.Writer
+	errOut   io.Writer
+	editor   *Editor
+}
+
+// NewSession returns a session that passes commands to run. It starts at root,
+// which may be empty to start without a network.
+func NewSession(root string, commands []Command, run Runner, out, errOut io.Writer) (*Session, error) {
+	s := &Session{commands: commands, run: run, out: out, errOut: errOut}
+	if root != "" {
+		if err := s.setRoot(root); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+type builtin struct {
+	usage string
+	help  string
+	run   func(s *Session, args []string) error
+}
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"net":     {"net <CIDR>", "Start over at a network", (*Session).cmdNet},
+		"split":   {"split <count>", "Divide the current network into subnets", (*Session).cmdSplit},
+		"ls":      {"ls", "List the subnets the current network was last split into", (*Session).cmdList},
+		"select":  {"select <n>", "Make subnet n of the last split the current network", (*Session).cmdSelect},
+		"up":      {"up", "Go back to the parent network and its subnets", (*Session).cmdUp},
+		"top":     {"top", "Go back to the network the shell started at", (*Session).cmdTop},
+		"pwd":     {"pwd", "Print the path to the current network", (*Session).cmdPwd},
+		"help":    {"help", "Show this help", (*Session).cmdHelp},
+		"history": {"history", "Show the lines entered so far", (*Session).cmdHistory},
+		"exit":    {"exit", "Leave the shell (also quit or Ctrl-D)", (*Session).cmdExit},
+		"quit":    {"quit", "", (*Session).cmdExit},
+	}
+}
+
+// errExit is returned by Exec when the shell should stop.
+var errExit = errors.New("exit")
+
+// Run reads and executes lines from e until the input ends or exit is entered.
+func (s *Session) Run(e *Editor) error {
+	s.editor = e
+	e.Complete = s.complete
+	prompt := ""
+	for {
+		if e.Interactive() {
+			prompt = s.Prompt()
+		}
+		line, err := e.ReadLine(prompt)
+		if errors.Is(err, ErrInterrupt) {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.Exec(line); err == errExit {
+			return nil
+		} else if err != nil {
+			fmt.Fprintf(s.errOut, "Error: %s\n", err)
+		}
+	}
+}
+
+// Prompt returns the breadcrumb of the current network, e.g. "10.0.0.0/16 > 10.0.0.0/18> ".
+func (s *Session) Prompt() string {
+	if len(s.path) == 0 {
+		return "cidr> "
+	}
+	return s.Breadcrumb() + "> "
+}
+
+// Breadcrumb returns the prefixes from the starting network to the current one.
+func (s *Session) Breadcrumb() string {
+	parts := make([]string, len(s.path))
+	for i, n := range s.path {
+		parts[i] = n.Prefix().String()
+	}
+	return strings.Join(parts, " > ")
+}
+
+// Current returns the current network, or nil if none is set.
+func (s *Session) Current() types.Network {
+	if len(s.path) == 0 {
+		return nil
+	}
+	return s.path[len(s.path)-1]
+}
+
+// Exec runs one line: a shell command, or a cidr command, which is given
+// the current network as its first argument if it takes one.
+func (s *Session) Exec(line string) error {
+	args := strings.Fields(line)
+	if len(args) == 0 {
+		return nil
+	}
+	if b, ok := builtins[args[0]]; ok {
+		return b.run(s, args[1:])
+	}
+
+	c, ok := s.command(args[0])
+	if !ok {
+		return fmt.Errorf("unknown command %q, type help for a list", args[0])
+	}
+	if c.TakesNetwork {
+		cur := s.Current()
+		if cur == nil {
+			return fmt.Errorf("%s needs a network, set one with net <CIDR>", args[0])
+		}
+		args = slices.Insert(args, 1, cur.Prefix().String())
+	}
+	return s.run(args)
+}
+
+func (s *Session) command(name string) (Command, bool) {
+	for _, c := range s.commands {
+		if c.Name == name || slices.Contains(c.Aliases, name) {
+			return c, true
+		}
+	}
+	return Command{}, false
+}
+
+func (s *Session) setRoot(cidr string) error {
+	n, err := network.New(cidr)
+	if err != nil {
+		return err
+	}
+	s.path, s.splits = []types.Network{n}, [][]netip.Prefix{nil}
+	return nil
+}
+
+// subnets returns the subnets the current network was last split into.
+func (s *Session) subnets() []netip.Prefix {
+	if len(s.splits) == 0 {
+		return nil
+	}
+	return s.splits[len(s.splits)-1]
+}
+
+func (s *Session) cmdNet(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: net <CIDR>")
+	}
+	if err := s.setRoot(strings.Join(args, " ")); err != nil {
+		return err
+	}
+	return s.cmdPwd(nil)
+}
+
+func (s *Session) cmdSplit(args []string) error {
+	cur := s.Current()
+	if cur == nil {
+		return fmt.Errorf("no network, set one with net <CIDR>")
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: split <count>")
+	}
+	c, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid subnet count: %s", args[0])
+	}
+	if c <= 0 {
+		return fmt.Errorf("count must be > 0")
+	}
+	if c > network.MaxSubnets {
+		return fmt.Errorf("count must be at most %d", network.MaxSubnets)
+	}
+	subnets, err := cur.Divide(c, false)
+	if err != nil {
+		return err
+	}
+	s.splits[len(s.splits)-1] = subnets
+	return s.cmdList(nil)
+}
+
+func (s *Session) cmdList([]string) error {
+	if len(s.subnets()) == 0 {
+		return fmt.Errorf("nothing to list, split the network first")
+	}
+	for i, p := range s.subnets() {
+		fmt.Fprintf(s.out, "%3d  %s\n", i+1, p)
+	}
+	return nil
+}
+
+func (s *Session) cmdSelect(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: select <n>")
+	}
+	subnets := s.subnets()
+	if len(subnets) == 0 {
+		return fmt.Errorf("nothing to select, split the network first")
+	}
+	i, err := strconv.Atoi(args[0])
+	if err != nil || i < 1 || i > len(subnets) {
+		return fmt.Errorf("select a subnet from 1 to %d", len(subnets))
+	}
+	n, err := network.New(subnets[i-1].String())
+	if err != nil {
+		return err
+	}
+	s.path, s.splits = append(s.path, n), append(s.splits, nil)
+	return s.cmdPwd(nil)
+}
+
+// cmdUp returns to the parent, whose last split can be selected from again.
+func (s *Session) cmdUp([]string) error {
+	if len(s.path) < 2 {
+		return fmt.Errorf("already at the top")
+	}
+	s.path, s.splits = s.path[:len(s.path)-1], s.splits[:len(s.splits)-1]
+	return s.cmdPwd(nil)
+}
+
+func (s *Session) cmdTop([]string) error {
+	if len(s.path) == 0 {
+		return fmt.Errorf("no network, set one with net <CIDR>")
+	}
+	s.path, s.splits = s.path[:1], s.splits[:1]
+	return s.cmdPwd(nil)
+}
+
+func (s *Session) cmdPwd([]string) error {
+	if len(s.path) == 0 {
+		return fmt.Errorf("no network, set one with net <CIDR>")
+	}
+	fmt.Fprintln(s.out, s.Breadcrumb())
+	return nil
+}
+
+func (s *Session) cmdHistory([]string) error {
+	if s.editor == nil {
+		return nil
+	}
+	for i, h := range s.editor.History {
+		fmt.Fprintf(s.out, "%5d  %s\n", i+1, h)
+	}
+	return nil
+}
+
+func (s *Session) cmdExit([]string) error {
+	return errExit
+}
+
+func (s *Session) cmdHelp([]string) error {
+	names := slices.Sorted(func(yield func(string) bool) {
+		for name := range builtins {
+			if !yield(name) {
+				return
+			}
+		}
+	})
+	fmt.Fprintln(s.out, "Shell commands:")
+	for _, name := range names {
+		if b := builtins[name]; b.help != "" {
+			fmt.Fprintf(s.out, "  %-15s %s\n", b.usage, b.help)
+		}
+	}
+	fmt.Fprintln(s.out, "cidr commands, given the current network as their first argument where they take one:")
+	var cmds []string
+	for _, c := range s.commands {
+		cmds = append(cmds, c.Name)
+	}
+	fmt.Fprintf(s.out, "  %s\n", strings.Join(cmds, ", "))
+	return nil
+}
+
+// complete completes shell and cidr command names, and subnet numbers after select.
+func (s *Session) complete(words []string, word string) []string {
+	var candidates []string
+	switch {
+	case len(words) == 0:
+		for name, b := range builtins {
+			if b.help != "" || name == "quit" {
+				candidates = append(candidates, name)
+			}
+		}
+		for _, c := range s.commands {
+			candidates = append(candidates, c.Name)
+		}
+	case len(words) == 1 && words[0] == "select":
+		for i := range s.subnets() {
+			candidates = append(candidates, strconv.Itoa(i+1))
+		}
+	}
+
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, word) {
+			out = append(out, c)
+		}
+	}
+	slices.Sort(out)
+	return slices.Compact(out)
+}
@@ -0,0 +1,122 @@
+package shell
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+)
+
+// A whole session: split and select move the path, aliases get the current network,
+// bad commands do not end the session and nothing after exit is run.
+func TestSession(t *testing.T) {
+	// arrange
+	var ran [][]string
+	run := func(args []string) error {
+		ran = append(ran, args)
+		return nil
+	}
+	commands := []Command{{Name: "explain", Aliases: []string{"e"}, TakesNetwork: true}, {Name: "merge"}}
+	var out, errOut strings.Builder
+	s, err := NewSession("10.0.0.0/16", commands, run, &out, &errOut)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := "split 4\nselect 2\ne\ncontains 10.0.64.1\nsplit 2\nselect 1\nmerge 10.0.0.0/24 10.0.1.0/24\nup\nselect 3\nselect 2\nexit\nexplain\n"
+
+	// act
+	err = s.Run(NewEditor(strings.NewReader(in), io.Discard))
+
+	// assert
+	if err != nil {
+		t.Fatalf(`Run returned error: %s`, err)
+	}
+	if got, want := s.Breadcrumb(), "10.0.0.0/16 > 10.0.64.0/18 > 10.0.96.0/19"; got != want {
+		t.Errorf(`Breadcrumb = %q, want %q`, got, want)
+	}
+	wantRan := []string{"e 10.0.64.0/18", "merge 10.0.0.0/24 10.0.1.0/24"}
+	if len(ran) != len(wantRan) {
+		t.Fatalf(`ran %v, want %v`, ran, wantRan)
+	}
+	for i, args := range ran {
+		if got := strings.Join(args, " "); got != wantRan[i] {
+			t.Errorf(`run %d = %q, want %q`, i, got, wantRan[i])
+		}
+	}
+	if !strings.Contains(out.String(), "  2  10.0.64.0/18\n") {
+		t.Errorf(`split output = %q, want numbered subnets`, out.String())
+	}
+	for _, want := range []string{`unknown command "contains"`, "select a subnet from 1 to 2"} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf(`errors = %q, want %q`, errOut.String(), want)
+		}
+	}
+}
+
+// Without a root network the shell still starts, and net sets one in any notation.
+func TestSessionWithoutNetwork(t *testing.T) {
+	s, _ := NewSession("", []Command{{Name: "explain", TakesNetwork: true}}, func([]string) error { return nil }, io.Discard, io.Discard)
+	if s.Prompt() != "cidr> " {
+		t.Errorf(`Prompt = %q, want "cidr> "`, s.Prompt())
+	}
+	if err := s.Exec("explain"); err == nil {
+		t.Errorf(`Exec(explain) returned no error without a network`)
+	}
+	if err := s.Exec("net 192.168.0.0 255.255.255.0"); err != nil || s.Breadcrumb() != "192.168.0.0/24" {
+		t.Errorf(`net = %q, %v, want 192.168.0.0/24`, s.Breadcrumb(), err)
+	}
+}
+
+// split shares the limits of divide, so it cannot list more than MaxSubnets.
+func TestSplitCount(t *testing.T) {
+	// arrange
+	s, err := NewSession("::/0", nil, func([]string) error { return nil }, io.Discard, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"split -1":         "count must be > 0",
+		"split 0":          "count must be > 0",
+		"split 1073741824": "count must be at most 1048576",
+	}
+	for line, want := range tests {
+		err := s.Exec(line)
+		if err == nil || err.Error() != want {
+			t.Errorf(`Exec(%q) error = %v, want %q`, line, err, want)
+		}
+	}
+}
+
+// Keys are sent as a terminal sends them, escape sequences included.
+func TestEditor(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    string
+		history []string
+		want    string
+	}{
+		{"typed", "split 4\r", nil, "split 4"},
+		{"backspace", "splitt\x7f 4\r", nil, "split 4"},
+		{"cursor", "slit 4\x1b[D\x1b[D\x1b[D\x1b[D\x1b[Dp\r", nil, "split 4"},
+		{"line start and end", "plit\x01s\x05 4\r", nil, "split 4"},
+		{"history", "\x1b[A\x1b[A\r", []string{"split 4", "select 2"}, "split 4"},
+		{"history back to draft", "up\x1b[A\x1b[B\r", []string{"split 4"}, "up"},
+		{"complete one", "sel\t2\r", nil, "select 2"},
+		{"complete common prefix", "s\t\r", nil, "s"},
+		{"complete subcommand", "expl\t\r", nil, "explain "},
+		{"list candidates", "ex\t\r", nil, "ex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := NewSession("10.0.0.0/16", []Command{{Name: "explain"}, {Name: "extract"}, {Name: "exclude"}}, nil, io.Discard, io.Discard)
+			e := &Editor{in: bufio.NewReader(strings.NewReader(tt.keys)), out: io.Discard, History: tt.history, Complete: s.complete}
+			got, err := e.edit("> ")
+			if err != nil {
+				t.Fatalf(`edit returned error: %s`, err)
+			}
+			if got != tt.want {
+				t.Errorf(`edit(%q) = %q, want %q`, tt.keys, got, tt.want)
+			}
+		})
+	}
+}
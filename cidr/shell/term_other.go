@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package shell
+
+import "errors"
+
+// Line editing is not supported here, so lines are read as typed.
+
+type termState struct{}
+
+func isTerminal(fd uintptr) bool { return false }
+
+func makeRaw(fd uintptr) (*termState, error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
+
+func restore(fd uintptr, s *termState) error { return nil }
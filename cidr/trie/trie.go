@@ -0,0 +1,216 @@
+package trie
+
+import (
+	"iter"
+	"math/bits"
+	"net/netip"
+)
+
+// Trie maps prefixes to values and answers longest-prefix-match queries.
+// It is a path-compressed binary (patricia) trie: a node only exists where a
+// prefix is stored or where two stored prefixes branch off, so lookups take at
+// most one step per stored prefix on the path instead of one per bit.
+// IPv4 and IPv6 prefixes are kept in separate trees.
+// The zero value is an empty trie ready to use. A Trie is not safe for concurrent writes.
+type Trie[V any] struct {
+	v4, v6 *node[V]
+	size   int
+}
+
+type node[V any] struct {
+	prefix   netip.Prefix // always masked
+	value    V
+	hasValue bool // false for nodes that only exist to branch
+	child    [2]*node[V]
+}
+
+// Len returns the number of prefixes in the trie.
+func (t *Trie[V]) Len() int {
+	return t.size
+}
+
+// Insert stores v for p, replacing the value of an existing equal prefix.
+// Host bits of p are ignored.
+func (t *Trie[V]) Insert(p netip.Prefix, v V) {
+	p = p.Masked()
+	np := t.root(p.Addr())
+	for {
+		n := *np
+		if n == nil {
+			*np = &node[V]{prefix: p, value: v, hasValue: true}
+			t.size++
+			return
+		}
+
+		c := commonBits(n.prefix, p)
+		switch {
+		case c == n.prefix.Bits() && c == p.Bits():
+			// Same prefix: replace the value.
+			if !n.hasValue {
+				t.size++
+			}
+			n.value, n.hasValue = v, true
+			return
+
+		case c == n.prefix.Bits():
+			// n contains p: descend on the first bit after n.
+			np = &n.child[bit(p.Addr(), c)]
+
+		case c == p.Bits():
+			// p contains n: p takes the place of n, with n below it.
+			nn := &node[V]{prefix: p, value: v, hasValue: true}
+			nn.child[bit(n.prefix.Addr(), c)] = n
+			*np = nn
+			t.size++
+			return
+
+		default:
+			// p and n diverge after c bits: branch there.
+			branch := &node[V]{prefix: netip.PrefixFrom(p.Addr(), c).Masked()}
+			branch.child[bit(n.prefix.Addr(), c)] = n
+			branch.child[bit(p.Addr(), c)] = &node[V]{prefix: p, value: v, hasValue: true}
+			*np = branch
+			t.size++
+			return
+		}
+	}
+}
+
+// Delete removes p from the trie and reports whether it was present.
+func (t *Trie[V]) Delete(p netip.Prefix) bool {
+	p = p.Masked()
+	var parent **node[V]
+	np := t.root(p.Addr())
+	for *np != nil {
+		n := *np
+		if commonBits(n.prefix, p) < n.prefix.Bits() {
+			return false
+		}
+		if n.prefix.Bits() == p.Bits() {
+			if !n.hasValue {
+				return false
+			}
+			var zero V
+			n.value, n.hasValue = zero, false
+			t.size--
+			// Drop nodes that no longer store or branch anything.
+			compact(np)
+			if parent != nil {
+				compact(parent)
+			}
+			return true
+		}
+		parent = np
+		np = &n.child[bit(p.Addr(), n.prefix.Bits())]
+	}
+	return false
+}
+
+// Get returns the value stored for exactly p.
+func (t *Trie[V]) Get(p netip.Prefix) (V, bool) {
+	p = p.Masked()
+	n := *t.root(p.Addr())
+	for n != nil && commonBits(n.prefix, p) == n.prefix.Bits() {
+		if n.prefix.Bits() == p.Bits() {
+			return n.value, n.hasValue
+		}
+		n = n.child[bit(p.Addr(), n.prefix.Bits())]
+	}
+	var zero V
+	return zero, false
+}
+
+// Lookup returns the longest stored prefix that contains a, and its value.
+func (t *Trie[V]) Lookup(a netip.Addr) (netip.Prefix, V, bool) {
+	var (
+		best  netip.Prefix
+		value V
+		found bool
+	)
+	for p, v := range t.Covering(netip.PrefixFrom(a, a.BitLen())) {
+		best, value, found = p, v, true
+	}
+	return best, value, found
+}
+
+// Covering returns an iterator over the stored prefixes that contain p
+// (including p itself), from the shortest to the longest.
+func (t *Trie[V]) Covering(p netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	p = p.Masked()
+	return func(yield func(netip.Prefix, V) bool) {
+		n := *t.root(p.Addr())
+		for n != nil && n.prefix.Bits() <= p.Bits() && n.prefix.Contains(p.Addr()) {
+			if n.hasValue && !yield(n.prefix, n.value) {
+				return
+			}
+			if n.prefix.Bits() == p.Bits() {
+				return
+			}
+			n = n.child[bit(p.Addr(), n.prefix.Bits())]
+		}
+	}
+}
+
+// All returns an iterator over every stored prefix, IPv4 first,
+// ordered by address and then by prefix length.
+func (t *Trie[V]) All() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		_ = walk(t.v4, yield) && walk(t.v6, yield)
+	}
+}
+
+func walk[V any](n *node[V], yield func(netip.Prefix, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.hasValue && !yield(n.prefix, n.value) {
+		return false
+	}
+	return walk(n.child[0], yield) && walk(n.child[1], yield)
+}
+
+func (t *Trie[V]) root(a netip.Addr) **node[V] {
+	if a.Is4() {
+		return &t.v4
+	}
+	return &t.v6
+}
+
+// compact removes a node without a value that has fewer than two children.
+func compact[V any](np **node[V]) {
+	n := *np
+	if n == nil || n.hasValue {
+		return
+	}
+	switch {
+	case n.child[0] == nil:
+		*np = n.child[1]
+	case n.child[1] == nil:
+		*np = n.child[0]
+	}
+}
+
+// bit returns bit i of a, counting from the most significant bit.
+func bit(a netip.Addr, i int) int {
+	if a.Is4() {
+		b := a.As4()
+		return int(b[i/8]>>(7-uint(i%8))) & 1
+	}
+	b := a.As16()
+	return int(b[i/8]>>(7-uint(i%8))) & 1
+}
+
+// commonBits returns the length of the longest prefix shared by a and b.
+func commonBits(a, b netip.Prefix) int {
+	limit := min(a.Bits(), b.Bits())
+	x, y := a.Addr().AsSlice(), b.Addr().AsSlice()
+	n := 0
+	for i := range x {
+		if d := x[i] ^ y[i]; d != 0 {
+			n += bits.LeadingZeros8(d)
+			break
+		}
+		n += 8
+	}
+	return min(n, limit)
+}
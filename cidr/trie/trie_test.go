@@ -0,0 +1,119 @@
+package trie
+
+import (
+	"math/rand"
+	"net/netip"
+	"testing"
+)
+
+// The longest match wins, v4 and v6 are kept apart, and a v4-mapped address
+// does not match the v4 prefix it maps to.
+func TestLookup(t *testing.T) {
+	// arrange
+	var tr Trie[string]
+	tr.Insert(netip.MustParsePrefix("10.0.0.0/8"), "corp")
+	tr.Insert(netip.MustParsePrefix("10.1.0.0/16"), "dc")
+	tr.Insert(netip.MustParsePrefix("10.1.2.0/24"), "rack")
+	tr.Insert(netip.MustParsePrefix("2001:db8::/32"), "v6")
+
+	tests := map[string]string{
+		"10.2.0.1":        "corp",
+		"10.1.3.4":        "dc",
+		"10.1.2.3":        "rack",
+		"2001:db8::1":     "v6",
+		"192.168.0.1":     "",
+		"2001:db9::1":     "",
+		"::ffff:10.0.0.1": "",
+	}
+	for addr, want := range tests {
+		a := netip.MustParseAddr(addr)
+		_, got, _ := tr.Lookup(a)
+		if got != want {
+			t.Errorf(`Lookup(%s) = %q, want match for %q`, addr, got, want)
+		}
+	}
+}
+
+// Deleting a /16 keeps the /24 below it and makes lookups under it fall back
+// to the /8; deleting a prefix that was never added reports false.
+func TestDelete(t *testing.T) {
+	// arrange
+	var tr Trie[int]
+	for i, s := range []string{"10.0.0.0/8", "10.1.0.0/16", "10.2.0.0/16", "10.1.2.0/24"} {
+		tr.Insert(netip.MustParsePrefix(s), i)
+	}
+
+	// act
+	deleted := tr.Delete(netip.MustParsePrefix("10.1.0.0/16"))
+	missing := tr.Delete(netip.MustParsePrefix("10.3.0.0/16"))
+
+	// assert
+	if !deleted || missing {
+		t.Errorf(`Delete() = %v, %v, want match for true, false`, deleted, missing)
+	}
+	if tr.Len() != 3 {
+		t.Errorf(`Len() = %d, want match for 3`, tr.Len())
+	}
+	if p, _, _ := tr.Lookup(netip.MustParseAddr("10.1.3.1")); p.String() != "10.0.0.0/8" {
+		t.Errorf(`Lookup(10.1.3.1) = %s, want match for 10.0.0.0/8`, p)
+	}
+	if _, ok := tr.Get(netip.MustParsePrefix("10.1.2.0/24")); !ok {
+		t.Errorf(`Get(10.1.2.0/24) = false, want match for true`)
+	}
+}
+
+// Random inserts and deletes, checked against a linear scan, to find the
+// node splits and merges the hand-written cases miss.
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randPrefix := func() netip.Prefix {
+		// Few distinct top bits so that the prefixes nest and branch often.
+		b := [4]byte{10, byte(r.Intn(4)), byte(r.Intn(256)), byte(r.Intn(256))}
+		return netip.PrefixFrom(netip.AddrFrom4(b), 8+r.Intn(25)).Masked()
+	}
+
+	var tr Trie[netip.Prefix]
+	stored := map[netip.Prefix]bool{}
+	for i := 0; i < 2000; i++ {
+		p := randPrefix()
+		if r.Intn(4) == 0 {
+			if tr.Delete(p) != stored[p] {
+				t.Fatalf(`Delete(%s) = %v, want match for %v`, p, !stored[p], stored[p])
+			}
+			delete(stored, p)
+			continue
+		}
+		tr.Insert(p, p)
+		stored[p] = true
+	}
+	if tr.Len() != len(stored) {
+		t.Fatalf(`Len() = %d, want match for %d`, tr.Len(), len(stored))
+	}
+
+	for i := 0; i < 2000; i++ {
+		a := randPrefix().Addr()
+
+		var want netip.Prefix
+		covering := 0
+		for p := range stored {
+			if p.Contains(a) {
+				covering++
+				if !want.IsValid() || p.Bits() > want.Bits() {
+					want = p
+				}
+			}
+		}
+
+		got, v, ok := tr.Lookup(a)
+		if ok != want.IsValid() || got != want || (ok && v != want) {
+			t.Fatalf(`Lookup(%s) = %s, want match for %s`, a, got, want)
+		}
+		n := 0
+		for range tr.Covering(netip.PrefixFrom(a, 32)) {
+			n++
+		}
+		if n != covering {
+			t.Fatalf(`Covering(%s) yielded %d prefixes, want match for %d`, a, n, covering)
+		}
+	}
+}